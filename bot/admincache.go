@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// adminCacheTTL 管理员列表缓存的有效期
+const adminCacheTTL = 10 * time.Minute
+
+// adminCacheEntry 单个聊天的管理员列表缓存
+type adminCacheEntry struct {
+	admins    []ChatMember
+	byUser    map[int64]ChatMember
+	fetchedAt time.Time
+}
+
+// adminCache 按聊天缓存管理员列表，避免每次权限检查都请求API
+type adminCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[int64]*adminCacheEntry
+}
+
+// newAdminCache 创建管理员缓存
+func newAdminCache(ttl time.Duration) *adminCache {
+	return &adminCache{
+		ttl:     ttl,
+		entries: make(map[int64]*adminCacheEntry),
+	}
+}
+
+// get 获取未过期的缓存
+func (c *adminCache) get(chatID int64) (*adminCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[chatID]
+	if !ok || time.Since(entry.fetchedAt) > c.ttl {
+		return nil, false
+	}
+	return entry, true
+}
+
+// set 写入聊天的管理员列表
+func (c *adminCache) set(chatID int64, admins []ChatMember) *adminCacheEntry {
+	entry := &adminCacheEntry{
+		admins:    admins,
+		byUser:    make(map[int64]ChatMember, len(admins)),
+		fetchedAt: time.Now(),
+	}
+	for _, admin := range admins {
+		if admin.User != nil {
+			entry.byUser[admin.User.ID] = admin
+		}
+	}
+
+	c.mu.Lock()
+	c.entries[chatID] = entry
+	c.mu.Unlock()
+
+	return entry
+}
+
+// invalidate 使聊天的缓存失效
+func (c *adminCache) invalidate(chatID int64) {
+	c.mu.Lock()
+	delete(c.entries, chatID)
+	c.mu.Unlock()
+}
+
+// chatAdmins 获取聊天管理员列表 (优先使用缓存)
+func (h *MessageHandler) chatAdmins(ctx context.Context, chatID int64) (*adminCacheEntry, error) {
+	if entry, ok := h.admins.get(chatID); ok {
+		return entry, nil
+	}
+
+	admins, err := h.client.GetChatAdministrators(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.admins.set(chatID, admins), nil
+}
+
+// adminMember 获取用户的管理员信息，不是管理员时返回 false
+func (h *MessageHandler) adminMember(ctx context.Context, chatID, userID int64) (ChatMember, bool) {
+	entry, err := h.chatAdmins(ctx, chatID)
+	if err != nil {
+		log.Printf("获取管理员列表时出错: %v", err)
+		return ChatMember{}, false
+	}
+
+	member, ok := entry.byUser[userID]
+	return member, ok
+}
+
+// isAnonymousAdmin 消息是否由匿名管理员以群组身份发送
+func isAnonymousAdmin(message *Message) bool {
+	return message.SenderChat != nil && message.SenderChat.ID == message.Chat.ID
+}
+
+// isSenderAdmin 检查消息发送者是否为管理员 (包括以群组身份发言的匿名管理员)
+func (h *MessageHandler) isSenderAdmin(ctx context.Context, message *Message) bool {
+	if isAnonymousAdmin(message) {
+		return true
+	}
+	if message.From == nil {
+		return false
+	}
+	return h.isUserAdmin(ctx, message.Chat.ID, message.From.ID)
+}
+
+// invalidateAdminsOnChange 成员的管理员身份变化时使缓存失效
+func (h *MessageHandler) invalidateAdminsOnChange(update *ChatMemberUpdated) {
+	if update.OldChatMember.IsAdmin() || update.NewChatMember.IsAdmin() {
+		h.admins.invalidate(update.Chat.ID)
+	}
+}
+
+// handleAdminCacheCommand 处理 /admincache 命令，强制刷新管理员缓存
+func (h *MessageHandler) handleAdminCacheCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	// 先检查权限，避免普通成员反复清空缓存触发 API 请求
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	h.admins.invalidate(message.Chat.ID)
+
+	entry, err := h.chatAdmins(ctx, message.Chat.ID)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 刷新管理员列表失败")
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已刷新管理员缓存，共 %d 位管理员", len(entry.admins)))
+}
@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// anonAdminCallbackPrefix 匿名管理员身份确认按钮的 callback_data 前缀
+const anonAdminCallbackPrefix = "anon:"
+
+// anonAdminConfirmTTL 身份确认按钮的有效期
+const anonAdminConfirmTTL = 2 * time.Minute
+
+// pendingAnonCommand 等待匿名管理员确认身份后执行的命令
+type pendingAnonCommand struct {
+	Message   *Message
+	Right     AdminRight
+	PromptID  int
+	ExpiresAt time.Time
+}
+
+// anonAdminRequests 保存等待确认的匿名管理员命令
+type anonAdminRequests struct {
+	mu      sync.Mutex
+	pending map[string]*pendingAnonCommand
+}
+
+// newAnonAdminRequests 创建匿名管理员命令队列
+func newAnonAdminRequests() *anonAdminRequests {
+	return &anonAdminRequests{pending: make(map[string]*pendingAnonCommand)}
+}
+
+// add 保存待确认的命令并返回其ID
+func (r *anonAdminRequests) add(cmd *pendingAnonCommand) (string, error) {
+	id, err := randomToken(6)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, p := range r.pending {
+		if now.After(p.ExpiresAt) {
+			delete(r.pending, k)
+		}
+	}
+
+	r.pending[id] = cmd
+	return id, nil
+}
+
+// peek 查看待确认的命令但不删除
+func (r *anonAdminRequests) peek(id string) (*pendingAnonCommand, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cmd, ok := r.pending[id]
+	if !ok || time.Now().After(cmd.ExpiresAt) {
+		return nil, false
+	}
+	return cmd, true
+}
+
+// setPrompt 记录确认按钮所在的消息ID，执行后需要删除
+func (r *anonAdminRequests) setPrompt(id string, messageID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cmd, ok := r.pending[id]; ok {
+		cmd.PromptID = messageID
+	}
+}
+
+// take 取出并删除待确认的命令
+func (r *anonAdminRequests) take(id string) (*pendingAnonCommand, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cmd, ok := r.pending[id]
+	delete(r.pending, id)
+	if !ok || time.Now().After(cmd.ExpiresAt) {
+		return nil, false
+	}
+	return cmd, true
+}
+
+// requestAnonAdminConfirmation 发送"我是管理员"按钮，等待匿名管理员确认身份
+func (h *MessageHandler) requestAnonAdminConfirmation(ctx context.Context, message *Message, right AdminRight) error {
+	cmd := &pendingAnonCommand{
+		Message:   message,
+		Right:     right,
+		ExpiresAt: time.Now().Add(anonAdminConfirmTTL),
+	}
+
+	id, err := h.anonRequests.add(cmd)
+	if err != nil {
+		return fmt.Errorf("生成确认请求失败: %w", err)
+	}
+
+	prompt, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID:           message.Chat.ID,
+		Text:             "🕵️ 您正在以匿名管理员身份操作，请点击下方按钮确认身份后执行命令",
+		ReplyToMessageID: message.MessageID,
+		ReplyMarkup: &InlineKeyboardMarkup{
+			InlineKeyboard: [][]InlineKeyboardButton{{
+				{Text: "🙋 我是管理员", CallbackData: anonAdminCallbackPrefix + id},
+			}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	h.anonRequests.setPrompt(id, prompt.MessageID)
+	return nil
+}
+
+// handleAnonAdminCallback 处理"我是管理员"按钮，确认身份后以点击者的身份执行原命令
+func (h *MessageHandler) handleAnonAdminCallback(ctx context.Context, query *CallbackQuery, id string) error {
+	cmd, ok := h.anonRequests.peek(id)
+	if !ok {
+		return h.answerCallback(ctx, query, "❌ 确认请求已过期，请重新发送命令", true)
+	}
+
+	chatID := cmd.Message.Chat.ID
+	if !h.memberHasRight(ctx, chatID, query.From.ID, cmd.Right) {
+		return h.answerCallback(ctx, query, fmt.Sprintf("❌ 您没有「%s」权限", adminRightLabels[cmd.Right]), true)
+	}
+
+	// 确认通过后才移除请求，避免无权限的成员点击后使按钮失效
+	cmd, ok = h.anonRequests.take(id)
+	if !ok {
+		return h.answerCallback(ctx, query, "❌ 确认请求已过期，请重新发送命令", true)
+	}
+
+	if cmd.PromptID != 0 {
+		if err := h.client.DeleteMessage(ctx, chatID, cmd.PromptID); err != nil {
+			log.Printf("删除身份确认消息失败: %v", err)
+		}
+	}
+
+	if err := h.answerCallback(ctx, query, "✅ 身份已确认", false); err != nil {
+		log.Printf("回答回调查询失败: %v", err)
+	}
+
+	// 以点击按钮的管理员身份重新执行命令
+	resolved := *cmd.Message
+	resolved.From = query.From
+	resolved.SenderChat = nil
+	return h.handleCommand(ctx, &resolved)
+}
+
+// randomToken 生成 n 字节的随机十六进制字符串
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
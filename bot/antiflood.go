@@ -0,0 +1,334 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// floodSweepInterval 清理不活跃用户计数的间隔
+const floodSweepInterval = time.Minute
+
+// floodDefaultMuteDuration 未配置禁言时长时的默认值
+const floodDefaultMuteDuration = 10 * time.Minute
+
+// floodEntry 用户在时间窗口内发送的一条消息
+type floodEntry struct {
+	at        time.Time
+	messageID int
+}
+
+// chatFloodState 单个聊天中各用户的消息计数
+type chatFloodState struct {
+	mu        sync.Mutex
+	users     map[int64][]floodEntry
+	lastSweep time.Time
+}
+
+// floodDetector 按聊天、按用户统计滑动时间窗口内的消息数
+// 每个聊天使用独立的锁，不同群组之间不会互相阻塞
+type floodDetector struct {
+	mu    sync.RWMutex
+	chats map[int64]*chatFloodState
+}
+
+// newFloodDetector 创建刷屏检测器
+func newFloodDetector() *floodDetector {
+	return &floodDetector{chats: make(map[int64]*chatFloodState)}
+}
+
+// chat 获取聊天的计数状态，不存在时创建
+func (d *floodDetector) chat(chatID int64) *chatFloodState {
+	d.mu.RLock()
+	state, ok := d.chats[chatID]
+	d.mu.RUnlock()
+	if ok {
+		return state
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if state, ok = d.chats[chatID]; !ok {
+		state = &chatFloodState{
+			users:     make(map[int64][]floodEntry),
+			lastSweep: time.Now(),
+		}
+		d.chats[chatID] = state
+	}
+	return state
+}
+
+// record 记录一条消息，超过阈值时返回窗口内所有消息的ID并重置该用户的计数
+func (d *floodDetector) record(chatID, userID int64, messageID int, limit int, window time.Duration) ([]int, bool) {
+	state := d.chat(chatID)
+	now := time.Now()
+	cutoff := now.Add(-window)
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	// 移除窗口外的记录，只保留最近 limit+1 条，内存占用与消息量无关
+	entries := state.users[userID]
+	start := 0
+	for start < len(entries) && !entries[start].at.After(cutoff) {
+		start++
+	}
+	entries = append(entries[start:], floodEntry{at: now, messageID: messageID})
+	if len(entries) > limit+1 {
+		entries = entries[len(entries)-limit-1:]
+	}
+
+	if len(entries) > limit {
+		ids := make([]int, len(entries))
+		for i, e := range entries {
+			ids[i] = e.messageID
+		}
+		delete(state.users, userID)
+		return ids, true
+	}
+	state.users[userID] = entries
+
+	// 定期清理窗口外已不活跃的用户
+	if now.Sub(state.lastSweep) > floodSweepInterval {
+		for id, list := range state.users {
+			if len(list) == 0 || !list[len(list)-1].at.After(cutoff) {
+				delete(state.users, id)
+			}
+		}
+		state.lastSweep = now
+	}
+
+	return nil, false
+}
+
+// reset 清空聊天的计数 (修改阈值后调用)
+func (d *floodDetector) reset(chatID int64) {
+	d.mu.Lock()
+	delete(d.chats, chatID)
+	d.mu.Unlock()
+}
+
+// checkFlood 检查消息是否触发防刷屏，触发时执行配置的动作并返回 true
+func (h *MessageHandler) checkFlood(ctx context.Context, message *Message) bool {
+	if message.Chat.Type != "group" && message.Chat.Type != "supergroup" {
+		return false
+	}
+	// 关联频道自动转发到讨论组的帖子不算刷屏
+	if message.IsAutomaticForward {
+		return false
+	}
+
+	// 以频道身份发言的消息按频道计数，防止借频道身份绕过
+	var senderID int64
+	switch {
+	case message.SenderChat != nil:
+		senderID = message.SenderChat.ID
+	case message.From != nil:
+		senderID = message.From.ID
+	default:
+		return false
+	}
+
+	settings := h.settings.Get(message.Chat.ID)
+	if !settings.IsModuleEnabled(ModuleAntiFlood) {
+		return false
+	}
+
+	flood := settings.Flood
+	if flood.Limit < 1 || flood.Window < 1 {
+		return false
+	}
+
+	ids, flooded := h.flood.record(message.Chat.ID, senderID, message.MessageID,
+		flood.Limit, time.Duration(flood.Window)*time.Second)
+	if !flooded {
+		return false
+	}
+
+	// 只在触发阈值后才检查管理员身份，避免每条消息都查询
+	if h.isSenderAdmin(ctx, message) {
+		return false
+	}
+
+	if err := h.applyFloodAction(ctx, message, flood, ids); err != nil {
+		log.Printf("执行防刷屏动作失败: %v", err)
+	}
+	return true
+}
+
+// applyFloodAction 对刷屏用户执行配置的动作
+func (h *MessageHandler) applyFloodAction(ctx context.Context, message *Message, flood FloodSettings, ids []int) error {
+	chatID := message.Chat.ID
+
+	// 频道身份无法禁言或踢出，只删除刷屏消息
+	if message.SenderChat != nil {
+		if err := h.client.DeleteMessages(ctx, chatID, ids); err != nil {
+			return err
+		}
+		_, err := h.client.SendMessage(ctx, SendMessageParams{
+			ChatID: chatID,
+			Text:   fmt.Sprintf("🌊 %s 因刷屏，消息已被删除", chatTitle(message.SenderChat)),
+		})
+		return err
+	}
+
+	user := message.From
+	reason := fmt.Sprintf("刷屏 (%d 秒内超过 %d 条消息)", flood.Window, flood.Limit)
+
+	action := ModAction{
+		Chat:     message.Chat,
+		Actor:    h.botUser,
+		TargetID: user.ID,
+		Target:   user,
+		Reason:   reason,
+		Message:  message,
+	}
+
+	var notice string
+	switch flood.Action {
+	case FloodActionDelete:
+		return h.client.DeleteMessages(ctx, chatID, ids)
+
+	case FloodActionKick:
+		action.Action = ModActionKick
+		notice = fmt.Sprintf("🌊 %s 因刷屏已被踢出群组", getUserName(user))
+
+	case FloodActionBan:
+		action.Action = ModActionBan
+		notice = fmt.Sprintf("🌊 %s 因刷屏已被封禁", getUserName(user))
+
+	default:
+		action.Action = ModActionMute
+		action.Duration = flood.muteDuration()
+		notice = fmt.Sprintf("🌊 %s 因刷屏已被禁言 %s", getUserName(user), formatDuration(action.Duration))
+	}
+
+	if err := h.punishMember(ctx, action); err != nil {
+		return err
+	}
+
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: chatID,
+		Text:   notice,
+	})
+	return err
+}
+
+// muteDuration 返回禁言时长
+func (f FloodSettings) muteDuration() time.Duration {
+	if f.MuteDuration <= 0 {
+		return floodDefaultMuteDuration
+	}
+	return time.Duration(f.MuteDuration) * time.Second
+}
+
+// floodActionRight 执行防刷屏动作所需的管理员权限
+func floodActionRight(action string) AdminRight {
+	if action == FloodActionDelete {
+		return RightDeleteMessages
+	}
+	return RightRestrictMembers
+}
+
+// handleSetFloodCommand 处理 /setflood 命令
+// 用法: /setflood <消息数> <秒数> [动作] [禁言时长] 或 /setflood off
+func (h *MessageHandler) handleSetFloodCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	current := h.settings.Get(message.Chat.ID)
+
+	if len(args) == 0 {
+		if ok, err := h.requireRight(ctx, message, floodActionRight(current.Flood.Action)); !ok {
+			return err
+		}
+		return h.sendReply(ctx, message, fmt.Sprintf("🌊 防刷屏: %s\n阈值: %d 秒内最多 %d 条消息\n触发动作: %s\n禁言时长: %s\n\n用法: /setflood <消息数> <秒数> [mute|kick|ban|delete] [禁言时长]\n关闭: /setflood off",
+			onOff(current.IsModuleEnabled(ModuleAntiFlood)), current.Flood.Window, current.Flood.Limit,
+			floodActionLabels[current.Flood.Action], formatDuration(current.Flood.muteDuration())))
+	}
+
+	if strings.ToLower(args[0]) == "off" {
+		if ok, err := h.requireRight(ctx, message, floodActionRight(current.Flood.Action)); !ok {
+			return err
+		}
+		_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+			s.Modules[ModuleAntiFlood] = false
+		})
+		if err != nil {
+			log.Printf("保存群组配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+		h.flood.reset(message.Chat.ID)
+		return h.sendReply(ctx, message, "✅ 已关闭防刷屏")
+	}
+
+	usage := "❌ 用法: /setflood <消息数> <秒数> [mute|kick|ban|delete] [禁言时长]"
+	if len(args) < 2 {
+		return h.sendReply(ctx, message, usage)
+	}
+
+	limit, err := strconv.Atoi(args[0])
+	if err != nil || limit < 1 {
+		return h.sendReply(ctx, message, "❌ 消息数必须为正整数")
+	}
+	window, err := strconv.Atoi(args[1])
+	if err != nil || window < 1 {
+		return h.sendReply(ctx, message, "❌ 秒数必须为正整数")
+	}
+
+	action := ""
+	if len(args) >= 3 {
+		action = strings.ToLower(args[2])
+		if _, ok := floodActionLabels[action]; !ok {
+			return h.sendReply(ctx, message, usage)
+		}
+	}
+
+	// 检查执行所选动作需要的权限，未指定动作时沿用当前动作
+	effective := action
+	if effective == "" {
+		effective = current.Flood.Action
+	}
+	if ok, err := h.requireRight(ctx, message, floodActionRight(effective)); !ok {
+		return err
+	}
+
+	var mute time.Duration
+	if len(args) >= 4 {
+		d, ok := parseDuration(args[3])
+		if !ok {
+			return h.sendReply(ctx, message, "❌ 无效的禁言时长，例如 30m、2h、1d")
+		}
+		mute = d
+	}
+
+	s, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		s.Modules[ModuleAntiFlood] = true
+		s.Flood.Limit = limit
+		s.Flood.Window = window
+		if action != "" {
+			s.Flood.Action = action
+		}
+		if mute > 0 {
+			s.Flood.MuteDuration = int64(mute / time.Second)
+		}
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+	h.flood.reset(message.Chat.ID)
+
+	text := fmt.Sprintf("✅ 已开启防刷屏\n阈值: %d 秒内最多 %d 条消息\n触发动作: %s",
+		s.Flood.Window, s.Flood.Limit, floodActionLabels[s.Flood.Action])
+	if s.Flood.Action == FloodActionMute {
+		text += "\n禁言时长: " + formatDuration(s.Flood.muteDuration())
+	}
+	return h.sendReply(ctx, message, text)
+}
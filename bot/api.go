@@ -6,13 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 const (
 	// BaseURL SafeW Bot API 基础URL
 	BaseURL = "https://api.safew.org/bot"
+	// FileBaseURL SafeW Bot API 文件下载基础URL
+	FileBaseURL = "https://api.safew.org/file/bot"
 )
 
 // ApiClient SafeW Bot API 客户端
@@ -20,6 +24,7 @@ type ApiClient struct {
 	token      string
 	httpClient *http.Client
 	baseURL    string
+	fileURL    string
 }
 
 // NewApiClient 创建新的API客户端
@@ -27,6 +32,7 @@ func NewApiClient(token string) *ApiClient {
 	return &ApiClient{
 		token:   token,
 		baseURL: BaseURL + token,
+		fileURL: FileBaseURL + token,
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
@@ -55,6 +61,44 @@ func (client *ApiClient) makeRequest(ctx context.Context, method, endpoint strin
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	return client.doRequest(req)
+}
+
+// makeMultipartRequest 以 multipart/form-data 方式发送请求 (用于上传文件)
+func (client *ApiClient) makeMultipartRequest(ctx context.Context, endpoint string, fields map[string]string, fileField, fileName string, data []byte) (*ApiResponse, error) {
+	url := fmt.Sprintf("%s/%s", client.baseURL, endpoint)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("failed to write field: %w", err)
+		}
+	}
+
+	part, err := writer.CreateFormFile(fileField, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return client.doRequest(req)
+}
+
+// doRequest 发送请求并解析API响应
+func (client *ApiClient) doRequest(req *http.Request) (*ApiResponse, error) {
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
@@ -95,9 +139,10 @@ func (client *ApiClient) GetMe(ctx context.Context) (*User, error) {
 
 // GetUpdatesParams getUpdates 方法的参数
 type GetUpdatesParams struct {
-	Offset  int `json:"offset,omitempty"`
-	Limit   int `json:"limit,omitempty"`
-	Timeout int `json:"timeout,omitempty"`
+	Offset         int      `json:"offset,omitempty"`
+	Limit          int      `json:"limit,omitempty"`
+	Timeout        int      `json:"timeout,omitempty"`
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
 }
 
 // GetUpdates 获取更新
@@ -117,13 +162,14 @@ func (client *ApiClient) GetUpdates(ctx context.Context, params GetUpdatesParams
 
 // SendMessageParams sendMessage 方法的参数
 type SendMessageParams struct {
-	ChatID                int64                `json:"chat_id"`
-	Text                  string               `json:"text"`
-	ParseMode             string               `json:"parse_mode,omitempty"`
-	DisableWebPagePreview bool                 `json:"disable_web_page_preview,omitempty"`
-	DisableNotification   bool                 `json:"disable_notification,omitempty"`
-	ReplyToMessageID      int                  `json:"reply_to_message_id,omitempty"`
-	ReplyMarkup           interface{}          `json:"reply_markup,omitempty"`
+	ChatID                int64           `json:"chat_id"`
+	Text                  string          `json:"text"`
+	ParseMode             string          `json:"parse_mode,omitempty"`
+	Entities              []MessageEntity `json:"entities,omitempty"`
+	DisableWebPagePreview bool            `json:"disable_web_page_preview,omitempty"`
+	DisableNotification   bool            `json:"disable_notification,omitempty"`
+	ReplyToMessageID      int             `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup           interface{}     `json:"reply_markup,omitempty"`
 }
 
 // SendMessage 发送消息
@@ -141,6 +187,96 @@ func (client *ApiClient) SendMessage(ctx context.Context, params SendMessagePara
 	return &message, nil
 }
 
+// 可以通过 file_id 重新发送的媒体类型
+const (
+	MediaTypePhoto    = "photo"
+	MediaTypeVideo    = "video"
+	MediaTypeDocument = "document"
+	MediaTypeAudio    = "audio"
+	MediaTypeVoice    = "voice"
+	MediaTypeSticker  = "sticker"
+)
+
+// mediaSendMethods 各媒体类型对应的发送方法
+var mediaSendMethods = map[string]string{
+	MediaTypePhoto:    "sendPhoto",
+	MediaTypeVideo:    "sendVideo",
+	MediaTypeDocument: "sendDocument",
+	MediaTypeAudio:    "sendAudio",
+	MediaTypeVoice:    "sendVoice",
+	MediaTypeSticker:  "sendSticker",
+}
+
+// SendMediaParams 通过 file_id 发送媒体的参数
+type SendMediaParams struct {
+	ChatID           int64
+	Type             string // 媒体类型，决定调用的方法和文件字段名
+	FileID           string
+	Caption          string // 贴纸不支持说明
+	CaptionEntities  []MessageEntity
+	ReplyToMessageID int
+	ReplyMarkup      interface{}
+}
+
+// SendMedia 通过 file_id 发送已上传过的媒体，无需重新上传文件
+func (client *ApiClient) SendMedia(ctx context.Context, params SendMediaParams) (*Message, error) {
+	method, ok := mediaSendMethods[params.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported media type: %s", params.Type)
+	}
+
+	body := map[string]interface{}{
+		"chat_id":   params.ChatID,
+		params.Type: params.FileID,
+	}
+	if params.Caption != "" && params.Type != MediaTypeSticker {
+		body["caption"] = params.Caption
+		if len(params.CaptionEntities) > 0 {
+			body["caption_entities"] = params.CaptionEntities
+		}
+	}
+	if params.ReplyToMessageID != 0 {
+		body["reply_to_message_id"] = params.ReplyToMessageID
+	}
+	if params.ReplyMarkup != nil {
+		body["reply_markup"] = params.ReplyMarkup
+	}
+
+	resp, err := client.makeRequest(ctx, "POST", method, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var message Message
+	if err := json.Unmarshal(resp.Result, &message); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	return &message, nil
+}
+
+// UploadDocument 上传文件并以文档形式发送
+func (client *ApiClient) UploadDocument(ctx context.Context, chatID int64, fileName string, data []byte, caption string) (*Message, error) {
+	fields := map[string]string{
+		"chat_id": strconv.FormatInt(chatID, 10),
+	}
+	if caption != "" {
+		fields["caption"] = caption
+	}
+
+	resp, err := client.makeMultipartRequest(ctx, "sendDocument", fields, "document", fileName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var message Message
+	if err := json.Unmarshal(resp.Result, &message); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	return &message, nil
+}
+
 // ForwardMessageParams forwardMessage 方法的参数
 type ForwardMessageParams struct {
 	ChatID              int64 `json:"chat_id"`
@@ -164,6 +300,103 @@ func (client *ApiClient) ForwardMessage(ctx context.Context, params ForwardMessa
 	return &message, nil
 }
 
+// CopyMessageParams copyMessage 方法的参数
+type CopyMessageParams struct {
+	ChatID              int64       `json:"chat_id"`
+	FromChatID          int64       `json:"from_chat_id"`
+	MessageID           int         `json:"message_id"`
+	Caption             *string     `json:"caption,omitempty"` // 为空时保留原说明
+	ParseMode           string      `json:"parse_mode,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int         `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         interface{} `json:"reply_markup,omitempty"`
+}
+
+// CopyMessage 复制消息 (不显示转发来源)
+func (client *ApiClient) CopyMessage(ctx context.Context, params CopyMessageParams) (*MessageID, error) {
+	resp, err := client.makeRequest(ctx, "POST", "copyMessage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var messageID MessageID
+	if err := json.Unmarshal(resp.Result, &messageID); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message id: %w", err)
+	}
+
+	return &messageID, nil
+}
+
+// ForwardMessagesParams forwardMessages 方法的参数
+type ForwardMessagesParams struct {
+	ChatID              int64 `json:"chat_id"`
+	FromChatID          int64 `json:"from_chat_id"`
+	MessageIDs          []int `json:"message_ids"`
+	DisableNotification bool  `json:"disable_notification,omitempty"`
+}
+
+// ForwardMessages 批量转发消息 (相册会保持为一组)
+func (client *ApiClient) ForwardMessages(ctx context.Context, params ForwardMessagesParams) ([]MessageID, error) {
+	resp, err := client.makeRequest(ctx, "POST", "forwardMessages", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var messageIDs []MessageID
+	if err := json.Unmarshal(resp.Result, &messageIDs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message ids: %w", err)
+	}
+
+	return messageIDs, nil
+}
+
+// CopyMessagesParams copyMessages 方法的参数
+type CopyMessagesParams struct {
+	ChatID              int64 `json:"chat_id"`
+	FromChatID          int64 `json:"from_chat_id"`
+	MessageIDs          []int `json:"message_ids"`
+	DisableNotification bool  `json:"disable_notification,omitempty"`
+	RemoveCaption       bool  `json:"remove_caption,omitempty"`
+}
+
+// CopyMessages 批量复制消息 (相册会保持为一组)
+func (client *ApiClient) CopyMessages(ctx context.Context, params CopyMessagesParams) ([]MessageID, error) {
+	resp, err := client.makeRequest(ctx, "POST", "copyMessages", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var messageIDs []MessageID
+	if err := json.Unmarshal(resp.Result, &messageIDs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message ids: %w", err)
+	}
+
+	return messageIDs, nil
+}
+
+// SendMediaGroupParams sendMediaGroup 方法的参数
+type SendMediaGroupParams struct {
+	ChatID              int64        `json:"chat_id"`
+	Media               []InputMedia `json:"media"`
+	DisableNotification bool         `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int          `json:"reply_to_message_id,omitempty"`
+}
+
+// SendMediaGroup 发送媒体组 (相册)
+func (client *ApiClient) SendMediaGroup(ctx context.Context, params SendMediaGroupParams) ([]Message, error) {
+	resp, err := client.makeRequest(ctx, "POST", "sendMediaGroup", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	if err := json.Unmarshal(resp.Result, &messages); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
+	}
+
+	return messages, nil
+}
+
 // GetChat 获取聊天信息
 func (client *ApiClient) GetChat(ctx context.Context, chatID int64) (*Chat, error) {
 	params := map[string]interface{}{
@@ -236,6 +469,19 @@ func (client *ApiClient) BanChatMember(ctx context.Context, params BanChatMember
 	return err
 }
 
+// UnbanChatMemberParams unbanChatMember 方法的参数
+type UnbanChatMemberParams struct {
+	ChatID       int64 `json:"chat_id"`
+	UserID       int64 `json:"user_id"`
+	OnlyIfBanned bool  `json:"only_if_banned,omitempty"`
+}
+
+// UnbanChatMember 解除聊天成员的封禁
+func (client *ApiClient) UnbanChatMember(ctx context.Context, params UnbanChatMemberParams) error {
+	_, err := client.makeRequest(ctx, "POST", "unbanChatMember", params)
+	return err
+}
+
 // PromoteChatMemberParams promoteChatMember 方法的参数
 type PromoteChatMemberParams struct {
 	ChatID              int64  `json:"chat_id"`
@@ -245,12 +491,13 @@ type PromoteChatMemberParams struct {
 	CanPostMessages     bool   `json:"can_post_messages,omitempty"`
 	CanEditMessages     bool   `json:"can_edit_messages,omitempty"`
 	CanDeleteMessages   bool   `json:"can_delete_messages,omitempty"`
-	CanManageVoiceChats bool   `json:"can_manage_voice_chats,omitempty"`
+	CanManageVideoChats bool   `json:"can_manage_video_chats,omitempty"`
 	CanRestrictMembers  bool   `json:"can_restrict_members,omitempty"`
 	CanPromoteMembers   bool   `json:"can_promote_members,omitempty"`
 	CanChangeInfo       bool   `json:"can_change_info,omitempty"`
 	CanInviteUsers      bool   `json:"can_invite_users,omitempty"`
 	CanPinMessages      bool   `json:"can_pin_messages,omitempty"`
+	CanManageTopics     bool   `json:"can_manage_topics,omitempty"`
 }
 
 // PromoteChatMember 提升聊天成员为管理员
@@ -273,6 +520,242 @@ func (client *ApiClient) RestrictChatMember(ctx context.Context, params Restrict
 	return err
 }
 
+// SetChatPermissionsParams setChatPermissions 方法的参数
+type SetChatPermissionsParams struct {
+	ChatID                        int64            `json:"chat_id"`
+	Permissions                   *ChatPermissions `json:"permissions"`
+	UseIndependentChatPermissions bool             `json:"use_independent_chat_permissions,omitempty"`
+}
+
+// SetChatPermissions 设置群组成员的默认权限
+func (client *ApiClient) SetChatPermissions(ctx context.Context, params SetChatPermissionsParams) error {
+	_, err := client.makeRequest(ctx, "POST", "setChatPermissions", params)
+	return err
+}
+
+// ExportChatInviteLink 生成新的主邀请链接 (旧的主链接失效)
+func (client *ApiClient) ExportChatInviteLink(ctx context.Context, chatID int64) (string, error) {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+	}
+
+	resp, err := client.makeRequest(ctx, "POST", "exportChatInviteLink", params)
+	if err != nil {
+		return "", err
+	}
+
+	var link string
+	if err := json.Unmarshal(resp.Result, &link); err != nil {
+		return "", fmt.Errorf("failed to unmarshal invite link: %w", err)
+	}
+
+	return link, nil
+}
+
+// ChatInviteLinkParams createChatInviteLink / editChatInviteLink 方法的参数
+type ChatInviteLinkParams struct {
+	ChatID             int64  `json:"chat_id"`
+	InviteLink         string `json:"invite_link,omitempty"` // 仅编辑时使用
+	Name               string `json:"name,omitempty"`
+	ExpireDate         int64  `json:"expire_date,omitempty"`
+	MemberLimit        int    `json:"member_limit,omitempty"`
+	CreatesJoinRequest bool   `json:"creates_join_request,omitempty"`
+}
+
+// CreateChatInviteLink 创建附加邀请链接
+func (client *ApiClient) CreateChatInviteLink(ctx context.Context, params ChatInviteLinkParams) (*ChatInviteLink, error) {
+	return client.inviteLinkRequest(ctx, "createChatInviteLink", params)
+}
+
+// EditChatInviteLink 编辑附加邀请链接
+func (client *ApiClient) EditChatInviteLink(ctx context.Context, params ChatInviteLinkParams) (*ChatInviteLink, error) {
+	return client.inviteLinkRequest(ctx, "editChatInviteLink", params)
+}
+
+// RevokeChatInviteLink 撤销邀请链接
+func (client *ApiClient) RevokeChatInviteLink(ctx context.Context, chatID int64, inviteLink string) (*ChatInviteLink, error) {
+	params := map[string]interface{}{
+		"chat_id":     chatID,
+		"invite_link": inviteLink,
+	}
+	return client.inviteLinkRequest(ctx, "revokeChatInviteLink", params)
+}
+
+// inviteLinkRequest 调用返回 ChatInviteLink 的方法
+func (client *ApiClient) inviteLinkRequest(ctx context.Context, method string, params interface{}) (*ChatInviteLink, error) {
+	resp, err := client.makeRequest(ctx, "POST", method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var link ChatInviteLink
+	if err := json.Unmarshal(resp.Result, &link); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal invite link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// GetFile 获取文件信息 (包括下载路径)
+func (client *ApiClient) GetFile(ctx context.Context, fileID string) (*File, error) {
+	params := map[string]interface{}{
+		"file_id": fileID,
+	}
+
+	resp, err := client.makeRequest(ctx, "POST", "getFile", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := json.Unmarshal(resp.Result, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal file: %w", err)
+	}
+
+	return &file, nil
+}
+
+// DownloadFile 下载 GetFile 返回的文件内容
+func (client *ApiClient) DownloadFile(ctx context.Context, filePath string) ([]byte, error) {
+	url := fmt.Sprintf("%s/%s", client.fileURL, filePath)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// GetChatMemberCount 获取聊天成员数量
+func (client *ApiClient) GetChatMemberCount(ctx context.Context, chatID int64) (int, error) {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+	}
+
+	resp, err := client.makeRequest(ctx, "POST", "getChatMemberCount", params)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := json.Unmarshal(resp.Result, &count); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal member count: %w", err)
+	}
+
+	return count, nil
+}
+
+// SetChatTitle 修改群组名称
+func (client *ApiClient) SetChatTitle(ctx context.Context, chatID int64, title string) error {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+		"title":   title,
+	}
+
+	_, err := client.makeRequest(ctx, "POST", "setChatTitle", params)
+	return err
+}
+
+// SetChatDescription 修改群组描述，description 为空时清除描述
+func (client *ApiClient) SetChatDescription(ctx context.Context, chatID int64, description string) error {
+	params := map[string]interface{}{
+		"chat_id":     chatID,
+		"description": description,
+	}
+
+	_, err := client.makeRequest(ctx, "POST", "setChatDescription", params)
+	return err
+}
+
+// SetChatPhoto 上传并设置群组头像
+func (client *ApiClient) SetChatPhoto(ctx context.Context, chatID int64, fileName string, photo []byte) error {
+	fields := map[string]string{
+		"chat_id": strconv.FormatInt(chatID, 10),
+	}
+
+	_, err := client.makeMultipartRequest(ctx, "setChatPhoto", fields, "photo", fileName, photo)
+	return err
+}
+
+// DeleteChatPhoto 删除群组头像
+func (client *ApiClient) DeleteChatPhoto(ctx context.Context, chatID int64) error {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+	}
+
+	_, err := client.makeRequest(ctx, "POST", "deleteChatPhoto", params)
+	return err
+}
+
+// SetChatAdministratorCustomTitle 设置由Bot提升的管理员的自定义头衔
+func (client *ApiClient) SetChatAdministratorCustomTitle(ctx context.Context, chatID, userID int64, customTitle string) error {
+	params := map[string]interface{}{
+		"chat_id":      chatID,
+		"user_id":      userID,
+		"custom_title": customTitle,
+	}
+
+	_, err := client.makeRequest(ctx, "POST", "setChatAdministratorCustomTitle", params)
+	return err
+}
+
+// PinChatMessageParams pinChatMessage 方法的参数
+type PinChatMessageParams struct {
+	ChatID              int64 `json:"chat_id"`
+	MessageID           int   `json:"message_id"`
+	DisableNotification bool  `json:"disable_notification,omitempty"`
+}
+
+// PinChatMessage 置顶消息
+func (client *ApiClient) PinChatMessage(ctx context.Context, params PinChatMessageParams) error {
+	_, err := client.makeRequest(ctx, "POST", "pinChatMessage", params)
+	return err
+}
+
+// UnpinChatMessage 取消置顶消息，messageID 为 0 时取消最近一条置顶
+func (client *ApiClient) UnpinChatMessage(ctx context.Context, chatID int64, messageID int) error {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+	}
+	if messageID != 0 {
+		params["message_id"] = messageID
+	}
+
+	_, err := client.makeRequest(ctx, "POST", "unpinChatMessage", params)
+	return err
+}
+
+// UnpinAllChatMessages 取消所有置顶消息
+func (client *ApiClient) UnpinAllChatMessages(ctx context.Context, chatID int64) error {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+	}
+
+	_, err := client.makeRequest(ctx, "POST", "unpinAllChatMessages", params)
+	return err
+}
+
+// LeaveChat 退出聊天
+func (client *ApiClient) LeaveChat(ctx context.Context, chatID int64) error {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+	}
+
+	_, err := client.makeRequest(ctx, "POST", "leaveChat", params)
+	return err
+}
+
 // DeleteMessage 删除消息
 func (client *ApiClient) DeleteMessage(ctx context.Context, chatID int64, messageID int) error {
 	params := map[string]interface{}{
@@ -282,4 +765,74 @@ func (client *ApiClient) DeleteMessage(ctx context.Context, chatID int64, messag
 
 	_, err := client.makeRequest(ctx, "POST", "deleteMessage", params)
 	return err
-} 
\ No newline at end of file
+}
+
+// DeleteMessagesLimit deleteMessages 单次请求最多删除的消息数
+const DeleteMessagesLimit = 100
+
+// DeleteMessages 批量删除消息，超过单次上限时自动分批请求
+// 不存在或无法删除的消息会被API跳过
+func (client *ApiClient) DeleteMessages(ctx context.Context, chatID int64, messageIDs []int) error {
+	for start := 0; start < len(messageIDs); start += DeleteMessagesLimit {
+		end := start + DeleteMessagesLimit
+		if end > len(messageIDs) {
+			end = len(messageIDs)
+		}
+
+		params := map[string]interface{}{
+			"chat_id":     chatID,
+			"message_ids": messageIDs[start:end],
+		}
+
+		if _, err := client.makeRequest(ctx, "POST", "deleteMessages", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AnswerCallbackQueryParams answerCallbackQuery 方法的参数
+type AnswerCallbackQueryParams struct {
+	CallbackQueryID string `json:"callback_query_id"`
+	Text            string `json:"text,omitempty"`
+	ShowAlert       bool   `json:"show_alert,omitempty"`
+}
+
+// AnswerCallbackQuery 回答回调查询
+func (client *ApiClient) AnswerCallbackQuery(ctx context.Context, params AnswerCallbackQueryParams) error {
+	_, err := client.makeRequest(ctx, "POST", "answerCallbackQuery", params)
+	return err
+}
+
+// EditMessageTextParams editMessageText 方法的参数
+type EditMessageTextParams struct {
+	ChatID                int64           `json:"chat_id"`
+	MessageID             int             `json:"message_id"`
+	Text                  string          `json:"text"`
+	ParseMode             string          `json:"parse_mode,omitempty"`
+	Entities              []MessageEntity `json:"entities,omitempty"`
+	DisableWebPagePreview bool            `json:"disable_web_page_preview,omitempty"`
+	ReplyMarkup           interface{}     `json:"reply_markup,omitempty"`
+}
+
+// EditMessageText 编辑消息文本
+func (client *ApiClient) EditMessageText(ctx context.Context, params EditMessageTextParams) error {
+	_, err := client.makeRequest(ctx, "POST", "editMessageText", params)
+	return err
+}
+
+// EditMessageCaptionParams editMessageCaption 方法的参数
+type EditMessageCaptionParams struct {
+	ChatID          int64           `json:"chat_id"`
+	MessageID       int             `json:"message_id"`
+	Caption         string          `json:"caption"`
+	ParseMode       string          `json:"parse_mode,omitempty"`
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+	ReplyMarkup     interface{}     `json:"reply_markup,omitempty"`
+}
+
+// EditMessageCaption 编辑消息说明
+func (client *ApiClient) EditMessageCaption(ctx context.Context, params EditMessageCaptionParams) error {
+	_, err := client.makeRequest(ctx, "POST", "editMessageCaption", params)
+	return err
+}
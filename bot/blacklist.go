@@ -0,0 +1,447 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 黑名单词条类型
+const (
+	BlacklistWord     = "word"     // 普通词语，忽略大小写的子串匹配
+	BlacklistWildcard = "wildcard" // 通配符，* 匹配任意字符，? 匹配单个字符
+	BlacklistRegex    = "regex"    // 正则表达式
+)
+
+// blacklistKindLabels 黑名单词条类型显示名称
+var blacklistKindLabels = map[string]string{
+	BlacklistWord:     "词语",
+	BlacklistWildcard: "通配符",
+	BlacklistRegex:    "正则",
+}
+
+// 黑名单触发动作
+const (
+	BlacklistActionDelete = "delete"
+	BlacklistActionWarn   = "warn"
+	BlacklistActionMute   = "mute"
+	BlacklistActionBan    = "ban"
+)
+
+// blacklistActionLabels 黑名单动作显示名称
+var blacklistActionLabels = map[string]string{
+	BlacklistActionDelete: "删除消息",
+	BlacklistActionWarn:   "删除并警告",
+	BlacklistActionMute:   "删除并禁言",
+	BlacklistActionBan:    "删除并封禁",
+}
+
+// blacklistDefaultMuteDuration 未配置禁言时长时的默认值
+const blacklistDefaultMuteDuration = time.Hour
+
+// BlacklistEntry 一条黑名单词条
+type BlacklistEntry struct {
+	Pattern   string `json:"pattern"`
+	Kind      string `json:"kind"`
+	CreatedBy int64  `json:"created_by"`
+}
+
+// ChatBlacklist 单个聊天的黑名单配置
+type ChatBlacklist struct {
+	Entries      []BlacklistEntry `json:"entries"`
+	Action       string           `json:"action"`
+	MuteDuration int64            `json:"mute_duration,omitempty"` // 禁言时长 (秒)
+}
+
+// muteDuration 返回禁言时长
+func (b *ChatBlacklist) muteDuration() time.Duration {
+	if b.MuteDuration <= 0 {
+		return blacklistDefaultMuteDuration
+	}
+	return time.Duration(b.MuteDuration) * time.Second
+}
+
+// BlacklistManager 管理各聊天的黑名单，并缓存编译后的匹配器
+type BlacklistManager struct {
+	mu       sync.RWMutex
+	storage  *Storage
+	chats    map[int64]*ChatBlacklist
+	matchers map[int64][]*regexp.Regexp // 与 Entries 一一对应
+}
+
+// NewBlacklistManager 创建黑名单管理器并从存储中加载
+func NewBlacklistManager(storage *Storage) (*BlacklistManager, error) {
+	m := &BlacklistManager{
+		storage:  storage,
+		chats:    make(map[int64]*ChatBlacklist),
+		matchers: make(map[int64][]*regexp.Regexp),
+	}
+
+	if err := storage.Load("blacklist", &m.chats); err != nil {
+		return nil, err
+	}
+
+	for chatID := range m.chats {
+		m.compile(chatID)
+	}
+
+	return m, nil
+}
+
+// compile 重新编译聊天的匹配器，调用方需持有写锁
+func (m *BlacklistManager) compile(chatID int64) {
+	list, ok := m.chats[chatID]
+	if !ok || len(list.Entries) == 0 {
+		delete(m.matchers, chatID)
+		return
+	}
+
+	matchers := make([]*regexp.Regexp, len(list.Entries))
+	for i, entry := range list.Entries {
+		re, err := compileBlacklistPattern(entry)
+		if err != nil {
+			log.Printf("黑名单词条 %q 无效，已忽略: %v", entry.Pattern, err)
+			continue
+		}
+		matchers[i] = re
+	}
+	m.matchers[chatID] = matchers
+}
+
+// compileBlacklistPattern 将黑名单词条编译为忽略大小写的正则表达式
+func compileBlacklistPattern(entry BlacklistEntry) (*regexp.Regexp, error) {
+	switch entry.Kind {
+	case BlacklistRegex:
+		return regexp.Compile("(?i)" + entry.Pattern)
+	case BlacklistWildcard:
+		var b strings.Builder
+		b.WriteString("(?is)")
+		for _, r := range entry.Pattern {
+			switch r {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		return regexp.Compile(b.String())
+	default:
+		return regexp.Compile("(?i)" + regexp.QuoteMeta(entry.Pattern))
+	}
+}
+
+// Get 获取聊天的黑名单 (返回副本)
+func (m *BlacklistManager) Get(chatID int64) ChatBlacklist {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list, ok := m.chats[chatID]
+	if !ok {
+		return ChatBlacklist{Action: BlacklistActionDelete}
+	}
+
+	copied := *list
+	copied.Entries = append([]BlacklistEntry(nil), list.Entries...)
+	return copied
+}
+
+// update 修改聊天的黑名单，保存后重新编译匹配器
+// 修改作用于副本，保存失败时恢复原来的黑名单
+func (m *BlacklistManager) update(chatID int64, fn func(list *ChatBlacklist) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := &ChatBlacklist{Action: BlacklistActionDelete}
+	old, ok := m.chats[chatID]
+	if ok {
+		copied := *old
+		copied.Entries = append([]BlacklistEntry(nil), old.Entries...)
+		list = &copied
+	}
+
+	if err := fn(list); err != nil {
+		return err
+	}
+
+	m.chats[chatID] = list
+	if err := m.storage.Save("blacklist", m.chats); err != nil {
+		if ok {
+			m.chats[chatID] = old
+		} else {
+			delete(m.chats, chatID)
+		}
+		return err
+	}
+
+	m.compile(chatID)
+	return nil
+}
+
+// Add 添加黑名单词条
+func (m *BlacklistManager) Add(chatID int64, entry BlacklistEntry) error {
+	if _, err := compileBlacklistPattern(entry); err != nil {
+		return fmt.Errorf("表达式无效: %w", err)
+	}
+
+	return m.update(chatID, func(list *ChatBlacklist) error {
+		for _, e := range list.Entries {
+			if e.Kind == entry.Kind && strings.EqualFold(e.Pattern, entry.Pattern) {
+				return fmt.Errorf("词条已存在")
+			}
+		}
+		list.Entries = append(list.Entries, entry)
+		return nil
+	})
+}
+
+// Remove 按序号 (从1开始) 或原文删除黑名单词条
+func (m *BlacklistManager) Remove(chatID int64, key string) (BlacklistEntry, error) {
+	var removed BlacklistEntry
+	err := m.update(chatID, func(list *ChatBlacklist) error {
+		index := -1
+		if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= len(list.Entries) {
+			index = n - 1
+		} else {
+			for i, e := range list.Entries {
+				if strings.EqualFold(e.Pattern, key) {
+					index = i
+					break
+				}
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("词条不存在")
+		}
+
+		removed = list.Entries[index]
+		list.Entries = append(list.Entries[:index], list.Entries[index+1:]...)
+		return nil
+	})
+	return removed, err
+}
+
+// SetAction 设置触发动作
+func (m *BlacklistManager) SetAction(chatID int64, action string, mute time.Duration) error {
+	return m.update(chatID, func(list *ChatBlacklist) error {
+		list.Action = action
+		if mute > 0 {
+			list.MuteDuration = int64(mute / time.Second)
+		}
+		return nil
+	})
+}
+
+// Match 检查文本是否命中黑名单，返回命中的词条
+func (m *BlacklistManager) Match(chatID int64, text string) (BlacklistEntry, bool) {
+	if text == "" {
+		return BlacklistEntry{}, false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matchers := m.matchers[chatID]
+	for i, re := range matchers {
+		if re != nil && re.MatchString(text) {
+			return m.chats[chatID].Entries[i], true
+		}
+	}
+	return BlacklistEntry{}, false
+}
+
+// checkBlacklist 检查消息是否命中黑名单，命中时执行配置的动作并返回 true
+func (h *MessageHandler) checkBlacklist(ctx context.Context, message *Message) bool {
+	if message.Chat.Type == "private" {
+		return false
+	}
+
+	entry, ok := h.blacklist.Match(message.Chat.ID, messageText(message))
+	if !ok {
+		return false
+	}
+
+	// 命中后才检查管理员身份，管理员不受黑名单限制
+	if h.isSenderAdmin(ctx, message) {
+		return false
+	}
+
+	if err := h.applyBlacklistAction(ctx, message, entry); err != nil {
+		log.Printf("执行黑名单动作失败: %v", err)
+	}
+	return true
+}
+
+// applyBlacklistAction 删除命中黑名单的消息并按配置处理发送者
+func (h *MessageHandler) applyBlacklistAction(ctx context.Context, message *Message, entry BlacklistEntry) error {
+	chatID := message.Chat.ID
+	list := h.blacklist.Get(chatID)
+
+	if err := h.client.DeleteMessage(ctx, chatID, message.MessageID); err != nil {
+		log.Printf("删除黑名单消息失败: %v", err)
+	}
+
+	// 以频道或群组身份发送的消息无法处罚具体用户
+	if message.From == nil || message.SenderChat != nil || list.Action == BlacklistActionDelete {
+		return nil
+	}
+
+	user := message.From
+	action := ModAction{
+		Chat:     message.Chat,
+		Actor:    h.botUser,
+		TargetID: user.ID,
+		Target:   user,
+		Reason:   fmt.Sprintf("命中黑名单: %s", entry.Pattern),
+	}
+
+	var notice string
+	switch list.Action {
+	case BlacklistActionMute:
+		action.Action = ModActionMute
+		action.Duration = list.muteDuration()
+		if err := h.punishMember(ctx, action); err != nil {
+			return err
+		}
+		notice = fmt.Sprintf("🚫 %s 的消息包含违禁内容，已被禁言 %s", getUserName(user), formatDuration(action.Duration))
+
+	case BlacklistActionBan:
+		action.Action = ModActionBan
+		if err := h.punishMember(ctx, action); err != nil {
+			return err
+		}
+		notice = fmt.Sprintf("🚫 %s 的消息包含违禁内容，已被封禁", getUserName(user))
+
+	default:
+		notice = fmt.Sprintf("⚠️ %s，您的消息包含违禁内容，已被删除", getUserName(user))
+	}
+
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: chatID,
+		Text:   notice,
+	})
+	return err
+}
+
+// parseBlacklistEntry 解析 /blacklist add 的参数
+// re:<表达式> 为正则，包含 * 或 ? 为通配符，其余为普通词语
+func parseBlacklistEntry(pattern string) BlacklistEntry {
+	switch {
+	case strings.HasPrefix(pattern, "re:"):
+		return BlacklistEntry{Pattern: strings.TrimPrefix(pattern, "re:"), Kind: BlacklistRegex}
+	case strings.ContainsAny(pattern, "*?"):
+		return BlacklistEntry{Pattern: pattern, Kind: BlacklistWildcard}
+	default:
+		return BlacklistEntry{Pattern: pattern, Kind: BlacklistWord}
+	}
+}
+
+// handleBlacklistCommand 处理 /blacklist 命令
+// 用法: /blacklist add <词语|通配符|re:正则> | remove <序号|词语> | list | action <动作> [禁言时长]
+func (h *MessageHandler) handleBlacklistCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightDeleteMessages); !ok {
+		return err
+	}
+
+	usage := "❌ 用法:\n/blacklist add <词语|通配符|re:正则>\n/blacklist remove <序号|词语>\n/blacklist list\n/blacklist action <delete|warn|mute|ban> [禁言时长]"
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, usage)
+	}
+
+	chatID := message.Chat.ID
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		// 词条可以包含空格，取命令后的完整文本
+		pattern := commandRemainder(message.Text, 2)
+		if pattern == "" {
+			return h.sendReply(ctx, message, usage)
+		}
+
+		entry := parseBlacklistEntry(pattern)
+		entry.CreatedBy = message.From.ID
+		if err := h.blacklist.Add(chatID, entry); err != nil {
+			return h.sendReply(ctx, message, "❌ 添加失败: "+err.Error())
+		}
+		return h.sendReply(ctx, message, fmt.Sprintf("✅ 已添加黑名单%s: %s", blacklistKindLabels[entry.Kind], entry.Pattern))
+
+	case "remove", "del":
+		key := commandRemainder(message.Text, 2)
+		if key == "" {
+			return h.sendReply(ctx, message, usage)
+		}
+
+		removed, err := h.blacklist.Remove(chatID, key)
+		if err != nil {
+			return h.sendReply(ctx, message, "❌ 删除失败: "+err.Error())
+		}
+		return h.sendReply(ctx, message, "✅ 已删除黑名单词条: "+removed.Pattern)
+
+	case "list":
+		list := h.blacklist.Get(chatID)
+		if len(list.Entries) == 0 {
+			return h.sendReply(ctx, message, "📭 当前群组没有黑名单词条")
+		}
+
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("🚫 黑名单 (%d):\n\n", len(list.Entries)))
+		for i, entry := range list.Entries {
+			b.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, blacklistKindLabels[entry.Kind], entry.Pattern))
+		}
+		b.WriteString(fmt.Sprintf("\n触发动作: %s", blacklistActionLabels[list.Action]))
+		if list.Action == BlacklistActionMute {
+			b.WriteString(fmt.Sprintf(" (%s)", formatDuration(list.muteDuration())))
+		}
+		return h.sendReply(ctx, message, b.String())
+
+	case "action":
+		if len(args) < 2 {
+			return h.sendReply(ctx, message, usage)
+		}
+
+		action := strings.ToLower(args[1])
+		if _, ok := blacklistActionLabels[action]; !ok {
+			return h.sendReply(ctx, message, usage)
+		}
+
+		var mute time.Duration
+		if len(args) >= 3 {
+			d, ok := parseDuration(args[2])
+			if !ok {
+				return h.sendReply(ctx, message, "❌ 无效的禁言时长，例如 30m、2h、1d")
+			}
+			mute = d
+		}
+
+		if err := h.blacklist.SetAction(chatID, action, mute); err != nil {
+			log.Printf("保存黑名单失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+		return h.sendReply(ctx, message, "✅ 黑名单触发动作已设置为: "+blacklistActionLabels[action])
+	}
+
+	return h.sendReply(ctx, message, usage)
+}
+
+// commandRemainder 返回命令文本中第 n 个字段之后的原始内容 (保留其中的空格)
+func commandRemainder(text string, n int) string {
+	rest := strings.TrimSpace(text)
+	for i := 0; i < n; i++ {
+		idx := strings.IndexFunc(rest, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' })
+		if idx < 0 {
+			return ""
+		}
+		rest = strings.TrimSpace(rest[idx:])
+	}
+	return rest
+}
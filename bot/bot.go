@@ -9,22 +9,53 @@ import (
 
 // Bot SafeW Bot 主结构
 type Bot struct {
-	client       *ApiClient
-	updateOffset int
-	pollTimeout  int
-	handlers     *MessageHandler
+	client         *ApiClient
+	updateOffset   int
+	pollTimeout    int
+	allowedUpdates []string
+	handlers       *MessageHandler
+}
+
+// Options Bot 运行参数
+type Options struct {
+	Token       string
+	PollTimeout int
+	DataDir     string // 持久化数据目录
+
+	DefaultForwardTarget int64    // 默认转发目标 (FORWARD_TARGET_CHAT)
+	AllowedUpdates       []string // 需要接收的更新类型，为空时接收 AllUpdateTypes
+
+	SuperAdmins           []int64 // 超级管理员用户ID
+	AllowedChats          []int64 // 聊天白名单，为空时不限制
+	AutoLeaveUnauthorized bool    // 被拉入白名单以外的聊天时自动退出
 }
 
 // NewBot 创建新的Bot实例
-func NewBot(token string, pollTimeout int) *Bot {
-	client := NewApiClient(token)
-	
-	return &Bot{
-		client:       client,
-		updateOffset: 0,
-		pollTimeout:  pollTimeout,
-		handlers:     NewMessageHandler(client),
+func NewBot(opts Options) (*Bot, error) {
+	client := NewApiClient(opts.Token)
+
+	storage, err := NewStorage(opts.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	handlers, err := NewMessageHandler(client, storage, opts)
+	if err != nil {
+		return nil, err
 	}
+
+	allowedUpdates := opts.AllowedUpdates
+	if len(allowedUpdates) == 0 {
+		allowedUpdates = AllUpdateTypes
+	}
+
+	return &Bot{
+		client:         client,
+		updateOffset:   0,
+		pollTimeout:    opts.PollTimeout,
+		allowedUpdates: allowedUpdates,
+		handlers:       handlers,
+	}, nil
 }
 
 // Start 启动Bot主循环
@@ -38,12 +69,17 @@ func (bot *Bot) Start(ctx context.Context) error {
 	}
 
 	log.Printf("Bot已启动: %s (@%s)", user.FirstName, user.Username)
+	bot.handlers.SetBotUser(user)
+
+	// 启动后台定时任务
+	go bot.handlers.RunScheduler(ctx)
 
 	// 开始长轮询循环
 	for {
 		select {
 		case <-ctx.Done():
 			log.Println("接收到停止信号，正在关闭Bot...")
+			bot.handlers.flushMirrorRecords(ctx)
 			return ctx.Err()
 		default:
 			if err := bot.processUpdates(ctx); err != nil {
@@ -58,9 +94,10 @@ func (bot *Bot) Start(ctx context.Context) error {
 // processUpdates 处理一轮更新
 func (bot *Bot) processUpdates(ctx context.Context) error {
 	params := GetUpdatesParams{
-		Offset:  bot.updateOffset,
-		Limit:   100,
-		Timeout: bot.pollTimeout,
+		Offset:         bot.updateOffset,
+		Limit:          100,
+		Timeout:        bot.pollTimeout,
+		AllowedUpdates: bot.allowedUpdates,
 	}
 
 	updates, err := bot.client.GetUpdates(ctx, params)
@@ -93,22 +130,82 @@ func (bot *Bot) handleUpdate(ctx context.Context, update Update) error {
 		return bot.handlers.HandleEditedMessage(ctx, update.EditedMessage)
 	}
 
+	// 处理频道消息
+	if update.ChannelPost != nil {
+		return bot.handlers.HandleChannelPost(ctx, update.ChannelPost)
+	}
+
+	// 处理编辑的频道消息
+	if update.EditedChannelPost != nil {
+		return bot.handlers.HandleEditedChannelPost(ctx, update.EditedChannelPost)
+	}
+
+	// 处理内联查询
+	if update.InlineQuery != nil {
+		return bot.handlers.HandleInlineQuery(ctx, update.InlineQuery)
+	}
+
+	// 处理用户选择的内联结果
+	if update.ChosenInlineResult != nil {
+		return bot.handlers.HandleChosenInlineResult(ctx, update.ChosenInlineResult)
+	}
+
 	// 处理回调查询
 	if update.CallbackQuery != nil {
 		return bot.handlers.HandleCallbackQuery(ctx, update.CallbackQuery)
 	}
 
+	// 处理配送查询
+	if update.ShippingQuery != nil {
+		return bot.handlers.HandleShippingQuery(ctx, update.ShippingQuery)
+	}
+
+	// 处理支付前确认
+	if update.PreCheckoutQuery != nil {
+		return bot.handlers.HandlePreCheckoutQuery(ctx, update.PreCheckoutQuery)
+	}
+
+	// 处理投票状态变更
+	if update.Poll != nil {
+		return bot.handlers.HandlePoll(ctx, update.Poll)
+	}
+
+	// 处理投票答案
+	if update.PollAnswer != nil {
+		return bot.handlers.HandlePollAnswer(ctx, update.PollAnswer)
+	}
+
+	// 处理Bot自身的成员状态变更
+	if update.MyChatMember != nil {
+		return bot.handlers.HandleMyChatMember(ctx, update.MyChatMember)
+	}
+
+	// 处理其他成员的状态变更
+	if update.ChatMember != nil {
+		return bot.handlers.HandleChatMember(ctx, update.ChatMember)
+	}
+
 	// 处理加群请求
 	if update.ChatJoinRequest != nil {
 		return bot.handlers.HandleChatJoinRequest(ctx, update.ChatJoinRequest)
 	}
 
+	// 处理表情回应
+	if update.MessageReaction != nil {
+		return bot.handlers.HandleMessageReaction(ctx, update.MessageReaction)
+	}
+
+	// 处理匿名表情回应数量
+	if update.MessageReactionCount != nil {
+		return bot.handlers.HandleMessageReactionCount(ctx, update.MessageReactionCount)
+	}
+
 	// 如果没有处理任何类型的更新，记录日志
-	log.Printf("收到未处理的更新类型: %+v", update)
+	log.Printf("收到未处理的更新类型: update_id=%d", update.UpdateID)
 	return nil
 }
 
 // Stop 停止Bot (优雅关闭)
 func (bot *Bot) Stop() {
 	log.Println("Bot正在关闭...")
-} 
\ No newline at end of file
+}
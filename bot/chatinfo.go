@@ -0,0 +1,176 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// 群组信息长度限制
+const (
+	maxChatTitleLength       = 128
+	maxChatDescriptionLength = 255
+	maxCustomTitleLength     = 16
+)
+
+// handleSetTitleCommand 处理 /settitle <名称> 命令
+func (h *MessageHandler) handleSetTitleCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightChangeInfo); !ok {
+		return err
+	}
+
+	title := commandRemainder(message.Text, 1)
+	if title == "" {
+		return h.sendReply(ctx, message, "❌ 用法: /settitle <群组名称>")
+	}
+	if len([]rune(title)) > maxChatTitleLength {
+		return h.sendReply(ctx, message, "❌ 群组名称最多 128 个字符")
+	}
+
+	if err := h.client.SetChatTitle(ctx, message.Chat.ID, title); err != nil {
+		return h.sendReply(ctx, message, "❌ 修改群组名称失败: "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, "✅ 群组名称已修改为: "+title)
+}
+
+// handleSetDescCommand 处理 /setdesc [描述] 命令，不带参数时清除描述
+func (h *MessageHandler) handleSetDescCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightChangeInfo); !ok {
+		return err
+	}
+
+	description := commandRemainder(message.Text, 1)
+	if len([]rune(description)) > maxChatDescriptionLength {
+		return h.sendReply(ctx, message, "❌ 群组描述最多 255 个字符")
+	}
+
+	if err := h.client.SetChatDescription(ctx, message.Chat.ID, description); err != nil {
+		return h.sendReply(ctx, message, "❌ 修改群组描述失败: "+err.Error())
+	}
+
+	if description == "" {
+		return h.sendReply(ctx, message, "✅ 已清除群组描述")
+	}
+	return h.sendReply(ctx, message, "✅ 群组描述已更新")
+}
+
+// handleSetPhotoCommand 处理 /setphoto 命令，将回复的图片设为群组头像
+func (h *MessageHandler) handleSetPhotoCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightChangeInfo); !ok {
+		return err
+	}
+
+	fileID := imageFileID(message.ReplyToMessage)
+	if fileID == "" {
+		return h.sendReply(ctx, message, "❌ 请回复一张图片")
+	}
+
+	file, err := h.client.GetFile(ctx, fileID)
+	if err != nil || file.FilePath == "" {
+		return h.sendReply(ctx, message, "❌ 获取图片失败")
+	}
+
+	data, err := h.client.DownloadFile(ctx, file.FilePath)
+	if err != nil {
+		log.Printf("下载图片失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 下载图片失败")
+	}
+
+	if err := h.client.SetChatPhoto(ctx, message.Chat.ID, path.Base(file.FilePath), data); err != nil {
+		return h.sendReply(ctx, message, "❌ 修改群组头像失败: "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, "✅ 群组头像已更新")
+}
+
+// imageFileID 取出消息中的图片文件ID (图片取最大尺寸，也支持以文件发送的图片)
+func imageFileID(message *Message) string {
+	if message == nil {
+		return ""
+	}
+	if len(message.Photo) > 0 {
+		return message.Photo[len(message.Photo)-1].FileID
+	}
+	if message.Document != nil && strings.HasPrefix(message.Document.MimeType, "image/") {
+		return message.Document.FileID
+	}
+	return ""
+}
+
+// handleDelPhotoCommand 处理 /delphoto 命令
+func (h *MessageHandler) handleDelPhotoCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightChangeInfo); !ok {
+		return err
+	}
+
+	if err := h.client.DeleteChatPhoto(ctx, message.Chat.ID); err != nil {
+		return h.sendReply(ctx, message, "❌ 删除群组头像失败: "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, "✅ 已删除群组头像")
+}
+
+// handleTitleCommand 处理 /title <用户ID> <头衔> 命令，设置管理员的自定义头衔
+// 也可以回复管理员的消息发送 /title <头衔>
+func (h *MessageHandler) handleTitleCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightChangeInfo); !ok {
+		return err
+	}
+
+	usage := "❌ 用法: /title <用户ID> <头衔>，或回复管理员的消息发送 /title <头衔>"
+
+	var userID int64
+	var title string
+	if len(args) >= 2 {
+		if id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "@"), 10, 64); err == nil {
+			userID = id
+			title = commandRemainder(message.Text, 2)
+		}
+	}
+	if userID == 0 && message.ReplyToMessage != nil && message.ReplyToMessage.From != nil && len(args) >= 1 {
+		userID = message.ReplyToMessage.From.ID
+		title = commandRemainder(message.Text, 1)
+	}
+	if userID == 0 || title == "" {
+		return h.sendReply(ctx, message, usage)
+	}
+
+	if len([]rune(title)) > maxCustomTitleLength {
+		return h.sendReply(ctx, message, "❌ 头衔最多 16 个字符")
+	}
+
+	// 只能修改由Bot提升的管理员，Bot需要添加管理员的权限
+	if !h.botHasRight(ctx, message.Chat.ID, RightPromoteMembers) {
+		return h.sendReply(ctx, message, "❌ Bot没有「添加管理员」权限，无法设置头衔")
+	}
+
+	if err := h.client.SetChatAdministratorCustomTitle(ctx, message.Chat.ID, userID, title); err != nil {
+		return h.sendReply(ctx, message, "❌ 设置头衔失败: "+err.Error())
+	}
+
+	h.admins.invalidate(message.Chat.ID)
+	return h.sendReply(ctx, message, "✅ 已设置头衔: "+title)
+}
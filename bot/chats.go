@@ -0,0 +1,236 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChatRecord Bot所在聊天的登记信息
+type ChatRecord struct {
+	ChatID     int64       `json:"chat_id"`
+	Title      string      `json:"title"`
+	Type       string      `json:"type"`
+	Status     string      `json:"status"` // Bot在该聊天中的状态
+	Member     *ChatMember `json:"member,omitempty"`
+	AddedBy    int64       `json:"added_by,omitempty"`
+	Authorized bool        `json:"authorized"`
+	JoinedAt   int64       `json:"joined_at"`
+	UpdatedAt  int64       `json:"updated_at"`
+}
+
+// IsActive Bot是否仍在该聊天中
+func (r *ChatRecord) IsActive() bool {
+	return r.Status != MemberStatusLeft && r.Status != MemberStatusKicked
+}
+
+// ChatRegistry 持久化记录Bot所在的所有聊天
+type ChatRegistry struct {
+	mu      sync.RWMutex
+	storage *Storage
+	chats   map[int64]*ChatRecord
+}
+
+// NewChatRegistry 创建聊天登记表并从存储中加载
+func NewChatRegistry(storage *Storage) (*ChatRegistry, error) {
+	r := &ChatRegistry{
+		storage: storage,
+		chats:   make(map[int64]*ChatRecord),
+	}
+
+	if err := storage.Load("chats", &r.chats); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Update 根据 my_chat_member 更新聊天记录，返回更新前后的记录
+func (r *ChatRegistry) Update(update *ChatMemberUpdated, authorized bool) (old ChatRecord, current ChatRecord, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().Unix()
+	record, ok := r.chats[update.Chat.ID]
+	if ok {
+		old = *record
+	} else {
+		record = &ChatRecord{ChatID: update.Chat.ID}
+		r.chats[update.Chat.ID] = record
+	}
+
+	wasActive := ok && record.IsActive()
+
+	record.Title = chatTitle(update.Chat)
+	record.Type = update.Chat.Type
+	record.Status = update.NewChatMember.Status
+	record.Member = update.NewChatMember
+	record.UpdatedAt = now
+
+	// 重新加入时刷新加入信息
+	if !wasActive && record.IsActive() {
+		record.JoinedAt = now
+		record.Authorized = authorized
+		if update.From != nil {
+			record.AddedBy = update.From.ID
+		}
+	}
+
+	if err := r.storage.Save("chats", r.chats); err != nil {
+		return old, *record, err
+	}
+
+	return old, *record, nil
+}
+
+// Get 获取聊天的登记信息
+func (r *ChatRegistry) Get(chatID int64) (ChatRecord, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.chats[chatID]
+	if !ok {
+		return ChatRecord{}, false
+	}
+	return *record, true
+}
+
+// List 列出所有登记的聊天 (按加入时间排序)
+func (r *ChatRegistry) List() []ChatRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]ChatRecord, 0, len(r.chats))
+	for _, record := range r.chats {
+		records = append(records, *record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].JoinedAt < records[j].JoinedAt
+	})
+	return records
+}
+
+// isChatAuthorized 检查聊天是否获准使用Bot
+// 未配置白名单时不做限制；否则白名单中的聊天或由超级管理员拉入的聊天视为已授权
+func (h *MessageHandler) isChatAuthorized(chatID int64, addedBy *User) bool {
+	if len(h.allowedChats) == 0 {
+		return true
+	}
+
+	if containsInt64(h.allowedChats, chatID) {
+		return true
+	}
+
+	return addedBy != nil && h.isSuperAdmin(addedBy.ID)
+}
+
+// isSuperAdmin 检查用户是否为超级管理员
+func (h *MessageHandler) isSuperAdmin(userID int64) bool {
+	return containsInt64(h.superAdmins, userID)
+}
+
+// trackMyChatMember 记录Bot的成员状态变更，并在需要时通知超级管理员或自动退出
+func (h *MessageHandler) trackMyChatMember(ctx context.Context, update *ChatMemberUpdated) error {
+	// 私聊中的状态变更 (用户屏蔽/解除屏蔽Bot) 不需要登记
+	if update.Chat.Type == "private" {
+		return nil
+	}
+
+	authorized := h.isChatAuthorized(update.Chat.ID, update.From)
+	old, current, err := h.chatRegistry.Update(update, authorized)
+	if err != nil {
+		log.Printf("保存聊天登记失败: %v", err)
+	}
+
+	wasActive := old.ChatID != 0 && old.IsActive()
+
+	switch {
+	case !wasActive && current.IsActive():
+		if current.Authorized {
+			return nil
+		}
+
+		h.notifySuperAdmins(ctx, fmt.Sprintf("⚠️ Bot被拉入未授权的聊天\n\n聊天: %s (%d)\n类型: %s\n操作人: %s",
+			current.Title, current.ChatID, current.Type, formatUser(update.From)))
+
+		if h.autoLeaveUnauthorized {
+			log.Printf("自动退出未授权的聊天: %s (%d)", current.Title, current.ChatID)
+			return h.client.LeaveChat(ctx, current.ChatID)
+		}
+
+	case wasActive && !current.IsActive():
+		h.notifySuperAdmins(ctx, fmt.Sprintf("🚪 Bot已被移出聊天\n\n聊天: %s (%d)\n状态: %s\n操作人: %s",
+			current.Title, current.ChatID, current.Status, formatUser(update.From)))
+
+	case old.Status == MemberStatusAdministrator && current.Status != MemberStatusAdministrator && current.IsActive():
+		h.notifySuperAdmins(ctx, fmt.Sprintf("⬇️ Bot的管理员权限已被撤销\n\n聊天: %s (%d)\n操作人: %s",
+			current.Title, current.ChatID, formatUser(update.From)))
+	}
+
+	return nil
+}
+
+// notifySuperAdmins 私聊通知所有超级管理员
+func (h *MessageHandler) notifySuperAdmins(ctx context.Context, text string) {
+	for _, adminID := range h.superAdmins {
+		_, err := h.client.SendMessage(ctx, SendMessageParams{
+			ChatID: adminID,
+			Text:   text,
+		})
+		if err != nil {
+			log.Printf("通知超级管理员 %d 失败: %v", adminID, err)
+		}
+	}
+}
+
+// handleChatsCommand 处理 /chats 命令 (仅超级管理员，仅限私聊)
+func (h *MessageHandler) handleChatsCommand(ctx context.Context, message *Message) error {
+	if message.From == nil || !h.isSuperAdmin(message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 此命令仅限超级管理员使用")
+	}
+
+	// 聊天列表包含其他群组的名称和ID，不在群组中公开发送
+	if message.Chat.Type != "private" {
+		return h.sendReply(ctx, message, "❌ 请在私聊中使用此命令")
+	}
+
+	var active, inactive []ChatRecord
+	for _, record := range h.chatRegistry.List() {
+		if record.IsActive() {
+			active = append(active, record)
+		} else {
+			inactive = append(inactive, record)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🗂 Bot所在的聊天 (%d):\n\n", len(active)))
+	for i, record := range active {
+		b.WriteString(fmt.Sprintf("%d. %s (%d)\n   类型: %s | 状态: %s | 加入: %s",
+			i+1, record.Title, record.ChatID, record.Type, record.Status,
+			time.Unix(record.JoinedAt, 0).Format("2006-01-02")))
+		if !record.Authorized {
+			b.WriteString(" | ⚠️ 未授权")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(inactive) > 0 {
+		b.WriteString(fmt.Sprintf("\n已退出的聊天: %d 个", len(inactive)))
+	}
+
+	return h.sendReply(ctx, message, b.String())
+}
+
+// chatTitle 获取聊天显示名称
+func chatTitle(chat *Chat) string {
+	if chat.Title != "" {
+		return chat.Title
+	}
+	return strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+}
@@ -0,0 +1,796 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 联邦相关限制
+const (
+	maxFederationNameLength = 64
+	maxFedBanListShown      = 50 // /fbanlist 最多显示的条数，完整列表使用 /fexport
+)
+
+// FedBan 一条联邦封禁记录
+type FedBan struct {
+	UserID   int64  `json:"user_id"`
+	Name     string `json:"name,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	BannedBy int64  `json:"banned_by"`
+	BannedAt int64  `json:"banned_at"`
+}
+
+// Federation 由多个群组组成的联邦，联邦封禁在所有成员群组中生效
+type Federation struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	OwnerID   int64            `json:"owner_id"`
+	Admins    []int64          `json:"admins,omitempty"`
+	Chats     []int64          `json:"chats,omitempty"`
+	Bans      map[int64]FedBan `json:"bans,omitempty"`
+	CreatedAt int64            `json:"created_at"`
+}
+
+// clone 深拷贝联邦，避免调用方修改共享状态
+func (f *Federation) clone() *Federation {
+	c := *f
+	c.Admins = append([]int64(nil), f.Admins...)
+	c.Chats = append([]int64(nil), f.Chats...)
+	c.Bans = make(map[int64]FedBan, len(f.Bans))
+	for id, ban := range f.Bans {
+		c.Bans[id] = ban
+	}
+	return &c
+}
+
+// IsAdmin 用户是否为联邦所有者或联邦管理员
+func (f *Federation) IsAdmin(userID int64) bool {
+	return userID == f.OwnerID || containsInt64(f.Admins, userID)
+}
+
+// SortedBans 按封禁时间排序的封禁记录
+func (f *Federation) SortedBans() []FedBan {
+	bans := make([]FedBan, 0, len(f.Bans))
+	for _, ban := range f.Bans {
+		bans = append(bans, ban)
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].BannedAt < bans[j].BannedAt
+	})
+	return bans
+}
+
+// FederationManager 管理所有联邦，并维护群组到联邦的索引
+type FederationManager struct {
+	mu      sync.RWMutex
+	storage *Storage
+	feds    map[string]*Federation
+	byChat  map[int64]string
+}
+
+// NewFederationManager 创建联邦管理器并从存储中加载
+func NewFederationManager(storage *Storage) (*FederationManager, error) {
+	m := &FederationManager{
+		storage: storage,
+		feds:    make(map[string]*Federation),
+		byChat:  make(map[int64]string),
+	}
+
+	if err := storage.Load("federations", &m.feds); err != nil {
+		return nil, err
+	}
+
+	for id, fed := range m.feds {
+		for _, chatID := range fed.Chats {
+			m.byChat[chatID] = id
+		}
+	}
+
+	return m, nil
+}
+
+// save 保存所有联邦，调用方需持有写锁
+func (m *FederationManager) save() error {
+	return m.storage.Save("federations", m.feds)
+}
+
+// update 修改联邦并保存，联邦不存在时返回错误
+// 修改作用于副本，保存失败时恢复原来的联邦
+func (m *FederationManager) update(fedID string, fn func(fed *Federation) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fed, ok := m.feds[fedID]
+	if !ok {
+		return fmt.Errorf("联邦不存在")
+	}
+
+	updated := fed.clone()
+	if err := fn(updated); err != nil {
+		return err
+	}
+
+	m.feds[fedID] = updated
+	if err := m.save(); err != nil {
+		m.feds[fedID] = fed
+		return err
+	}
+	return nil
+}
+
+// Create 创建联邦，每位用户只能拥有一个联邦
+func (m *FederationManager) Create(name string, ownerID int64) (*Federation, error) {
+	id, err := randomToken(6)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, fed := range m.feds {
+		if fed.OwnerID == ownerID {
+			return nil, fmt.Errorf("您已拥有联邦「%s」", fed.Name)
+		}
+	}
+
+	fed := &Federation{
+		ID:        id,
+		Name:      name,
+		OwnerID:   ownerID,
+		Bans:      make(map[int64]FedBan),
+		CreatedAt: time.Now().Unix(),
+	}
+	m.feds[id] = fed
+
+	if err := m.save(); err != nil {
+		delete(m.feds, id)
+		return nil, err
+	}
+	return fed.clone(), nil
+}
+
+// Delete 删除联邦，所有成员群组自动退出
+func (m *FederationManager) Delete(fedID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fed, ok := m.feds[fedID]
+	if !ok {
+		return fmt.Errorf("联邦不存在")
+	}
+
+	delete(m.feds, fedID)
+	if err := m.save(); err != nil {
+		m.feds[fedID] = fed
+		return err
+	}
+
+	for _, chatID := range fed.Chats {
+		delete(m.byChat, chatID)
+	}
+	return nil
+}
+
+// Get 获取联邦 (返回副本)
+func (m *FederationManager) Get(fedID string) (*Federation, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	fed, ok := m.feds[fedID]
+	if !ok {
+		return nil, false
+	}
+	return fed.clone(), true
+}
+
+// ByChat 获取群组所属的联邦 (返回副本)
+func (m *FederationManager) ByChat(chatID int64) (*Federation, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	fed, ok := m.feds[m.byChat[chatID]]
+	if !ok {
+		return nil, false
+	}
+	return fed.clone(), true
+}
+
+// ByOwner 获取用户拥有的联邦 (返回副本)
+func (m *FederationManager) ByOwner(ownerID int64) (*Federation, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, fed := range m.feds {
+		if fed.OwnerID == ownerID {
+			return fed.clone(), true
+		}
+	}
+	return nil, false
+}
+
+// IsBanned 检查用户是否被群组所属的联邦封禁，返回联邦名称和封禁记录
+func (m *FederationManager) IsBanned(chatID, userID int64) (string, FedBan, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	fed, ok := m.feds[m.byChat[chatID]]
+	if !ok {
+		return "", FedBan{}, false
+	}
+	ban, ok := fed.Bans[userID]
+	return fed.Name, ban, ok
+}
+
+// JoinChat 将群组加入联邦，一个群组只能加入一个联邦
+func (m *FederationManager) JoinChat(fedID string, chatID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fed, ok := m.feds[fedID]
+	if !ok {
+		return fmt.Errorf("联邦不存在")
+	}
+	if current, ok := m.feds[m.byChat[chatID]]; ok {
+		return fmt.Errorf("本群已加入联邦「%s」，请先发送 /leavefed 退出", current.Name)
+	}
+
+	updated := fed.clone()
+	updated.Chats = append(updated.Chats, chatID)
+
+	m.feds[fedID] = updated
+	if err := m.save(); err != nil {
+		m.feds[fedID] = fed
+		return err
+	}
+
+	m.byChat[chatID] = fedID
+	return nil
+}
+
+// LeaveChat 群组退出所属的联邦，返回退出的联邦
+func (m *FederationManager) LeaveChat(chatID int64) (*Federation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fedID := m.byChat[chatID]
+	fed, ok := m.feds[fedID]
+	if !ok {
+		return nil, fmt.Errorf("本群未加入任何联邦")
+	}
+
+	updated := fed.clone()
+	for i, id := range updated.Chats {
+		if id == chatID {
+			updated.Chats = append(updated.Chats[:i], updated.Chats[i+1:]...)
+			break
+		}
+	}
+
+	m.feds[fedID] = updated
+	if err := m.save(); err != nil {
+		m.feds[fedID] = fed
+		return nil, err
+	}
+
+	delete(m.byChat, chatID)
+	return updated.clone(), nil
+}
+
+// SetAdmin 添加或移除联邦管理员
+func (m *FederationManager) SetAdmin(fedID string, userID int64, admin bool) error {
+	return m.update(fedID, func(fed *Federation) error {
+		exists := containsInt64(fed.Admins, userID)
+		switch {
+		case admin && exists:
+			return fmt.Errorf("该用户已是联邦管理员")
+		case admin:
+			fed.Admins = append(fed.Admins, userID)
+		case !exists:
+			return fmt.Errorf("该用户不是联邦管理员")
+		default:
+			for i, id := range fed.Admins {
+				if id == userID {
+					fed.Admins = append(fed.Admins[:i], fed.Admins[i+1:]...)
+					break
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Ban 添加或更新联邦封禁记录
+func (m *FederationManager) Ban(fedID string, ban FedBan) error {
+	return m.update(fedID, func(fed *Federation) error {
+		if fed.IsAdmin(ban.UserID) {
+			return fmt.Errorf("不能封禁联邦管理员")
+		}
+		if fed.Bans == nil {
+			fed.Bans = make(map[int64]FedBan)
+		}
+		fed.Bans[ban.UserID] = ban
+		return nil
+	})
+}
+
+// Unban 移除联邦封禁记录
+func (m *FederationManager) Unban(fedID string, userID int64) (FedBan, error) {
+	var removed FedBan
+	err := m.update(fedID, func(fed *Federation) error {
+		ban, ok := fed.Bans[userID]
+		if !ok {
+			return fmt.Errorf("该用户未被联邦封禁")
+		}
+		removed = ban
+		delete(fed.Bans, userID)
+		return nil
+	})
+	return removed, err
+}
+
+// Import 合并导入的封禁记录，已存在的记录保持不变，返回新增的条数
+func (m *FederationManager) Import(fedID string, bans []FedBan) (int, error) {
+	added := 0
+	err := m.update(fedID, func(fed *Federation) error {
+		if fed.Bans == nil {
+			fed.Bans = make(map[int64]FedBan)
+		}
+		for _, ban := range bans {
+			if ban.UserID == 0 || fed.IsAdmin(ban.UserID) {
+				continue
+			}
+			if _, ok := fed.Bans[ban.UserID]; ok {
+				continue
+			}
+			fed.Bans[ban.UserID] = ban
+			added++
+		}
+		return nil
+	})
+	return added, err
+}
+
+// commandFederation 获取命令作用的联邦: 群组中为本群所属的联邦，私聊中为发送者拥有的联邦
+func (h *MessageHandler) commandFederation(message *Message) (*Federation, bool) {
+	if message.Chat.Type == "private" {
+		if message.From == nil {
+			return nil, false
+		}
+		return h.federations.ByOwner(message.From.ID)
+	}
+	return h.federations.ByChat(message.Chat.ID)
+}
+
+// noFederationText 未找到联邦时的提示
+func noFederationText(message *Message) string {
+	if message.Chat.Type == "private" {
+		return "❌ 您还没有创建联邦，发送 /newfed <名称> 创建"
+	}
+	return "❌ 本群未加入任何联邦"
+}
+
+// isChatCreator 用户是否为群主
+func (h *MessageHandler) isChatCreator(ctx context.Context, chatID, userID int64) bool {
+	member, ok := h.adminMember(ctx, chatID, userID)
+	return ok && member.Status == MemberStatusCreator
+}
+
+// handleNewFedCommand 处理 /newfed <名称> 命令
+func (h *MessageHandler) handleNewFedCommand(ctx context.Context, message *Message) error {
+	if message.From == nil || message.SenderChat != nil {
+		return h.sendReply(ctx, message, "❌ 请以个人身份创建联邦")
+	}
+
+	name := commandRemainder(message.Text, 1)
+	if name == "" {
+		return h.sendReply(ctx, message, "❌ 用法: /newfed <联邦名称>")
+	}
+	if len([]rune(name)) > maxFederationNameLength {
+		return h.sendReply(ctx, message, fmt.Sprintf("❌ 联邦名称最多 %d 个字符", maxFederationNameLength))
+	}
+
+	fed, err := h.federations.Create(name, message.From.ID)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 创建联邦失败: "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已创建联邦「%s」\n联邦ID: %s\n\n群主在群组中发送 /joinfed %s 即可加入", fed.Name, fed.ID, fed.ID))
+}
+
+// handleDelFedCommand 处理 /delfed 命令，删除自己拥有的联邦
+func (h *MessageHandler) handleDelFedCommand(ctx context.Context, message *Message) error {
+	if message.From == nil {
+		return nil
+	}
+
+	fed, ok := h.federations.ByOwner(message.From.ID)
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 您没有拥有的联邦")
+	}
+
+	if err := h.federations.Delete(fed.ID); err != nil {
+		log.Printf("保存联邦失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 删除联邦失败")
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已删除联邦「%s」，%d 个群组已自动退出", fed.Name, len(fed.Chats)))
+}
+
+// handleJoinFedCommand 处理 /joinfed <联邦ID> 命令，仅群主可用
+func (h *MessageHandler) handleJoinFedCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if message.From == nil || !h.isChatCreator(ctx, message.Chat.ID, message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 只有群主可以让群组加入联邦")
+	}
+
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, "❌ 用法: /joinfed <联邦ID>")
+	}
+
+	if err := h.federations.JoinChat(args[0], message.Chat.ID); err != nil {
+		return h.sendReply(ctx, message, "❌ 加入联邦失败: "+err.Error())
+	}
+
+	fed, _ := h.federations.Get(args[0])
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 本群已加入联邦「%s」，联邦封禁的用户将在本群被自动封禁", fed.Name))
+}
+
+// handleLeaveFedCommand 处理 /leavefed 命令，仅群主可用
+func (h *MessageHandler) handleLeaveFedCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if message.From == nil || !h.isChatCreator(ctx, message.Chat.ID, message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 只有群主可以让群组退出联邦")
+	}
+
+	fed, err := h.federations.LeaveChat(message.Chat.ID)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 本群已退出联邦「%s」", fed.Name))
+}
+
+// handleFedInfoCommand 处理 /fedinfo 命令
+func (h *MessageHandler) handleFedInfoCommand(ctx context.Context, message *Message) error {
+	fed, ok := h.commandFederation(message)
+	if !ok {
+		return h.sendReply(ctx, message, noFederationText(message))
+	}
+
+	admins := "无"
+	if len(fed.Admins) > 0 {
+		ids := make([]string, len(fed.Admins))
+		for i, id := range fed.Admins {
+			ids[i] = fmt.Sprintf("%d", id)
+		}
+		admins = strings.Join(ids, ", ")
+	}
+
+	text := fmt.Sprintf("🏛 联邦信息\n\n名称: %s\nID: %s\n所有者: %d\n联邦管理员: %s\n成员群组: %d 个\n封禁用户: %d 人\n创建时间: %s",
+		fed.Name, fed.ID, fed.OwnerID, admins, len(fed.Chats), len(fed.Bans),
+		time.Unix(fed.CreatedAt, 0).Format("2006-01-02 15:04"))
+	return h.sendReply(ctx, message, text)
+}
+
+// handleFedAdminCommand 处理 /fpromote 和 /fdemote 命令，由联邦所有者添加或移除联邦管理员
+func (h *MessageHandler) handleFedAdminCommand(ctx context.Context, message *Message, args []string, promote bool) error {
+	fed, ok := h.commandFederation(message)
+	if !ok {
+		return h.sendReply(ctx, message, noFederationText(message))
+	}
+	if message.From == nil || message.From.ID != fed.OwnerID {
+		return h.sendReply(ctx, message, "❌ 只有联邦所有者可以管理联邦管理员")
+	}
+
+	userID, target, _, ok := parseTargetUser(message, args)
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 用法: /fpromote <用户ID> 或 /fdemote <用户ID>，也可以回复用户的消息")
+	}
+	if userID == fed.OwnerID {
+		return h.sendReply(ctx, message, "❌ 联邦所有者无需设置为联邦管理员")
+	}
+
+	if err := h.federations.SetAdmin(fed.ID, userID, promote); err != nil {
+		return h.sendReply(ctx, message, "❌ "+err.Error())
+	}
+
+	name := fmt.Sprintf("%d", userID)
+	if target != nil {
+		name = formatUser(target)
+	}
+	if promote {
+		return h.sendReply(ctx, message, fmt.Sprintf("✅ 已将 %s 设为联邦「%s」的管理员", name, fed.Name))
+	}
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已移除 %s 的联邦管理员身份", name))
+}
+
+// handleFedBanCommand 处理 /fban <用户ID> [原因] 命令，在联邦所有群组中封禁用户
+func (h *MessageHandler) handleFedBanCommand(ctx context.Context, message *Message, args []string) error {
+	fed, ok := h.commandFederation(message)
+	if !ok {
+		return h.sendReply(ctx, message, noFederationText(message))
+	}
+	if message.From == nil || !fed.IsAdmin(message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 只有联邦所有者和联邦管理员可以使用联邦封禁")
+	}
+
+	userID, target, rest, ok := parseTargetUser(message, args)
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 用法: /fban <用户ID> [原因]，或回复用户的消息发送 /fban [原因]")
+	}
+	if h.botUser != nil && userID == h.botUser.ID {
+		return h.sendReply(ctx, message, "❌ 不能封禁Bot")
+	}
+
+	reason := strings.Join(rest, " ")
+	ban := FedBan{
+		UserID:   userID,
+		Reason:   reason,
+		BannedBy: message.From.ID,
+		BannedAt: time.Now().Unix(),
+	}
+	if target != nil {
+		ban.Name = getUserName(target)
+	}
+
+	if err := h.federations.Ban(fed.ID, ban); err != nil {
+		return h.sendReply(ctx, message, "❌ 联邦封禁失败: "+err.Error())
+	}
+
+	banned := 0
+	for _, chatID := range fed.Chats {
+		if err := h.client.BanChatMember(ctx, BanChatMemberParams{ChatID: chatID, UserID: userID}); err != nil {
+			log.Printf("在群组 %d 执行联邦封禁失败: %v", chatID, err)
+			continue
+		}
+		banned++
+
+		h.logModAction(ctx, ModAction{
+			Action:   ModActionFedBan,
+			Chat:     h.registeredChat(chatID),
+			Actor:    message.From,
+			TargetID: userID,
+			Target:   target,
+			Reason:   fmt.Sprintf("%s: %s", fed.Name, banReason(reason)),
+		})
+	}
+
+	name := fmt.Sprintf("%d", userID)
+	if target != nil {
+		name = formatUser(target)
+	}
+	return h.sendReply(ctx, message, fmt.Sprintf("🚫 已在联邦「%s」中封禁 %s\n原因: %s\n已在 %d/%d 个群组中执行",
+		fed.Name, name, banReason(reason), banned, len(fed.Chats)))
+}
+
+// handleFedUnbanCommand 处理 /unfban <用户ID> 命令，在联邦所有群组中解除封禁
+func (h *MessageHandler) handleFedUnbanCommand(ctx context.Context, message *Message, args []string) error {
+	fed, ok := h.commandFederation(message)
+	if !ok {
+		return h.sendReply(ctx, message, noFederationText(message))
+	}
+	if message.From == nil || !fed.IsAdmin(message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 只有联邦所有者和联邦管理员可以解除联邦封禁")
+	}
+
+	userID, target, _, ok := parseTargetUser(message, args)
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 用法: /unfban <用户ID>")
+	}
+
+	if _, err := h.federations.Unban(fed.ID, userID); err != nil {
+		return h.sendReply(ctx, message, "❌ "+err.Error())
+	}
+
+	unbanned := 0
+	for _, chatID := range fed.Chats {
+		err := h.client.UnbanChatMember(ctx, UnbanChatMemberParams{ChatID: chatID, UserID: userID, OnlyIfBanned: true})
+		if err != nil {
+			log.Printf("在群组 %d 解除联邦封禁失败: %v", chatID, err)
+			continue
+		}
+		unbanned++
+
+		h.logModAction(ctx, ModAction{
+			Action:   ModActionFedUnban,
+			Chat:     h.registeredChat(chatID),
+			Actor:    message.From,
+			TargetID: userID,
+			Target:   target,
+			Reason:   fed.Name,
+		})
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已在联邦「%s」中解除 %d 的封禁\n已在 %d/%d 个群组中执行",
+		fed.Name, userID, unbanned, len(fed.Chats)))
+}
+
+// registeredChat 获取登记的群组信息用于管理日志，未登记时只有ID
+func (h *MessageHandler) registeredChat(chatID int64) *Chat {
+	if record, ok := h.chatRegistry.Get(chatID); ok {
+		return &Chat{ID: chatID, Title: record.Title, Type: record.Type}
+	}
+	return &Chat{ID: chatID}
+}
+
+// banReason 返回封禁原因，未填写时显示默认文字
+func banReason(reason string) string {
+	if reason == "" {
+		return "未填写"
+	}
+	return reason
+}
+
+// handleFedBanListCommand 处理 /fbanlist 命令
+func (h *MessageHandler) handleFedBanListCommand(ctx context.Context, message *Message) error {
+	fed, ok := h.commandFederation(message)
+	if !ok {
+		return h.sendReply(ctx, message, noFederationText(message))
+	}
+	if message.From == nil || !fed.IsAdmin(message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 只有联邦所有者和联邦管理员可以查看封禁列表")
+	}
+
+	if len(fed.Bans) == 0 {
+		return h.sendReply(ctx, message, fmt.Sprintf("📭 联邦「%s」没有封禁记录", fed.Name))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🚫 联邦「%s」封禁列表 (共 %d 人)\n\n", fed.Name, len(fed.Bans)))
+	for i, ban := range fed.SortedBans() {
+		if i == maxFedBanListShown {
+			b.WriteString(fmt.Sprintf("\n... 仅显示前 %d 条，完整列表请使用 /fexport", maxFedBanListShown))
+			break
+		}
+		name := ""
+		if ban.Name != "" {
+			name = " " + ban.Name
+		}
+		b.WriteString(fmt.Sprintf("%d.%s (%d) - %s\n", i+1, name, ban.UserID, banReason(ban.Reason)))
+	}
+
+	return h.sendReply(ctx, message, b.String())
+}
+
+// handleFedExportCommand 处理 /fexport 命令，以 JSON 文件导出封禁列表
+func (h *MessageHandler) handleFedExportCommand(ctx context.Context, message *Message) error {
+	fed, ok := h.commandFederation(message)
+	if !ok {
+		return h.sendReply(ctx, message, noFederationText(message))
+	}
+	if message.From == nil || !fed.IsAdmin(message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 只有联邦所有者和联邦管理员可以导出封禁列表")
+	}
+
+	data, err := json.MarshalIndent(fed.SortedBans(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("导出封禁列表失败: %w", err)
+	}
+
+	caption := fmt.Sprintf("联邦「%s」封禁列表，共 %d 人", fed.Name, len(fed.Bans))
+	if _, err := h.client.UploadDocument(ctx, message.Chat.ID, "fbans-"+fed.ID+".json", data, caption); err != nil {
+		return h.sendReply(ctx, message, "❌ 发送文件失败: "+err.Error())
+	}
+	return nil
+}
+
+// handleFedImportCommand 处理 /fimport 命令，回复 /fexport 导出的文件合并封禁列表
+func (h *MessageHandler) handleFedImportCommand(ctx context.Context, message *Message) error {
+	fed, ok := h.commandFederation(message)
+	if !ok {
+		return h.sendReply(ctx, message, noFederationText(message))
+	}
+	if message.From == nil || message.From.ID != fed.OwnerID {
+		return h.sendReply(ctx, message, "❌ 只有联邦所有者可以导入封禁列表")
+	}
+
+	reply := message.ReplyToMessage
+	if reply == nil || reply.Document == nil {
+		return h.sendReply(ctx, message, "❌ 请回复 /fexport 导出的 JSON 文件")
+	}
+
+	file, err := h.client.GetFile(ctx, reply.Document.FileID)
+	if err != nil || file.FilePath == "" {
+		return h.sendReply(ctx, message, "❌ 获取文件失败")
+	}
+
+	data, err := h.client.DownloadFile(ctx, file.FilePath)
+	if err != nil {
+		log.Printf("下载文件失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 下载文件失败")
+	}
+
+	var bans []FedBan
+	if err := json.Unmarshal(data, &bans); err != nil {
+		return h.sendReply(ctx, message, "❌ 文件格式无效，请使用 /fexport 导出的文件")
+	}
+
+	added, err := h.federations.Import(fed.ID, bans)
+	if err != nil {
+		log.Printf("保存联邦失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 导入失败")
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已导入 %d 条封禁记录 (文件共 %d 条，已存在的记录保持不变)\n导入的用户在成员群组中入群或发言时会被自动封禁", added, len(bans)))
+}
+
+// checkFedBan 联邦封禁的用户入群或发言时自动封禁
+// 返回 true 表示消息发送者 (或所有新成员) 已被封禁，不再处理该消息
+func (h *MessageHandler) checkFedBan(ctx context.Context, message *Message) bool {
+	return h.enforceBanList(ctx, message, h.enforceFedBan)
+}
+
+// enforceBanList 对新成员或消息发送者执行封禁列表检查
+// 返回 true 表示消息发送者 (或所有新成员) 已被封禁，发送者的消息同时被删除
+func (h *MessageHandler) enforceBanList(ctx context.Context, message *Message, enforce func(ctx context.Context, chat *Chat, user *User) bool) bool {
+	if message.Chat.Type == "private" {
+		return false
+	}
+
+	if len(message.NewChatMembers) > 0 {
+		banned := 0
+		for _, member := range message.NewChatMembers {
+			if enforce(ctx, message.Chat, &member) {
+				banned++
+			}
+		}
+		return banned == len(message.NewChatMembers)
+	}
+
+	if message.From == nil || message.SenderChat != nil {
+		return false
+	}
+	if !enforce(ctx, message.Chat, message.From) {
+		return false
+	}
+
+	if err := h.client.DeleteMessage(ctx, message.Chat.ID, message.MessageID); err != nil {
+		log.Printf("删除被封禁用户的消息失败: %v", err)
+	}
+	return true
+}
+
+// enforceFedBan 用户被群组所属的联邦封禁时将其封禁，返回是否已封禁
+func (h *MessageHandler) enforceFedBan(ctx context.Context, chat *Chat, user *User) bool {
+	fedName, ban, ok := h.federations.IsBanned(chat.ID, user.ID)
+	if !ok {
+		return false
+	}
+
+	if err := h.client.BanChatMember(ctx, BanChatMemberParams{ChatID: chat.ID, UserID: user.ID}); err != nil {
+		log.Printf("执行联邦封禁失败: %v", err)
+		return false
+	}
+
+	reason := fmt.Sprintf("%s: %s", fedName, banReason(ban.Reason))
+	h.logModAction(ctx, ModAction{
+		Action:   ModActionFedBan,
+		Chat:     chat,
+		Actor:    h.botUser,
+		TargetID: user.ID,
+		Target:   user,
+		Reason:   reason,
+	})
+
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: chat.ID,
+		Text:   fmt.Sprintf("🚫 %s 已被联邦「%s」封禁，已自动移出\n原因: %s", getUserName(user), fedName, banReason(ban.Reason)),
+	})
+	if err != nil {
+		log.Printf("发送联邦封禁通知失败: %v", err)
+	}
+	return true
+}
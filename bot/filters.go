@@ -0,0 +1,438 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// filterAlternativeSeparator 分隔随机回复的多个备选内容
+const filterAlternativeSeparator = "%%%"
+
+// maxFilterTriggerLength 触发词最大长度
+const maxFilterTriggerLength = 64
+
+// filterPlaceholderPattern 回复内容中可用的占位符
+var filterPlaceholderPattern = regexp.MustCompile(`\{(first|last|fullname|username|id|chatname)\}`)
+
+// FilterReply 一个备选回复
+type FilterReply struct {
+	Text     string          `json:"text,omitempty"`
+	Entities []MessageEntity `json:"entities,omitempty"`
+}
+
+// Filter 一条关键词自动回复
+// 媒体回复只保存 file_id，多个备选回复共用同一媒体和按钮，只有文字不同
+type Filter struct {
+	Trigger   string                   `json:"trigger"`
+	Type      string                   `json:"type"` // text 或媒体类型，与笔记相同
+	FileID    string                   `json:"file_id,omitempty"`
+	Replies   []FilterReply            `json:"replies"`
+	Buttons   [][]InlineKeyboardButton `json:"buttons,omitempty"`
+	CreatedBy int64                    `json:"created_by"`
+	CreatedAt int64                    `json:"created_at"`
+}
+
+// FilterManager 管理各聊天的关键词自动回复
+type FilterManager struct {
+	mu      sync.RWMutex
+	storage *Storage
+	chats   map[int64]map[string]*Filter // 聊天 -> 小写触发词 -> 自动回复
+}
+
+// NewFilterManager 创建自动回复管理器并从存储中加载
+func NewFilterManager(storage *Storage) (*FilterManager, error) {
+	m := &FilterManager{
+		storage: storage,
+		chats:   make(map[int64]map[string]*Filter),
+	}
+
+	if err := storage.Load("filters", &m.chats); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Save 保存自动回复，同一触发词会被覆盖，返回是否覆盖了原有回复
+func (m *FilterManager) Save(chatID int64, filter Filter) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	filters, ok := m.chats[chatID]
+	if !ok {
+		filters = make(map[string]*Filter)
+		m.chats[chatID] = filters
+	}
+
+	key := strings.ToLower(filter.Trigger)
+	_, existed := filters[key]
+	filters[key] = &filter
+
+	return existed, m.storage.Save("filters", m.chats)
+}
+
+// Delete 删除自动回复
+func (m *FilterManager) Delete(chatID int64, trigger string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := strings.ToLower(trigger)
+	if _, ok := m.chats[chatID][key]; !ok {
+		return fmt.Errorf("触发词 %q 不存在", trigger)
+	}
+	delete(m.chats[chatID], key)
+	if len(m.chats[chatID]) == 0 {
+		delete(m.chats, chatID)
+	}
+
+	return m.storage.Save("filters", m.chats)
+}
+
+// List 列出聊天的所有自动回复 (按触发词排序)
+func (m *FilterManager) List(chatID int64) []Filter {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	filters := make([]Filter, 0, len(m.chats[chatID]))
+	for _, filter := range m.chats[chatID] {
+		filters = append(filters, *filter)
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].Trigger < filters[j].Trigger
+	})
+	return filters
+}
+
+// Match 查找消息命中的自动回复，同时命中多个时取最长的触发词
+func (m *FilterManager) Match(chatID int64, text string) (Filter, bool) {
+	if text == "" {
+		return Filter{}, false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.chats[chatID]) == 0 {
+		return Filter{}, false
+	}
+
+	lower := strings.ToLower(text)
+	var best *Filter
+	bestKey := ""
+	for key, filter := range m.chats[chatID] {
+		if len(key) <= len(bestKey) {
+			continue
+		}
+		if containsTrigger(lower, key) {
+			best, bestKey = filter, key
+		}
+	}
+
+	if best == nil {
+		return Filter{}, false
+	}
+	return *best, true
+}
+
+// isCJK 判断字符是否属于不以空格分词的文字 (中日韩)
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// isSpacedWordRune 判断字符是否属于以空格分词的单词 (字母、数字、下划线，不含中日韩文字)
+func isSpacedWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') && !isCJK(r)
+}
+
+// containsTrigger 判断文本中是否包含完整的触发词，text 和 trigger 均已转为小写
+// 触发词首尾为英文等以空格分词的字符时，相邻字符不能是同类字符 ("hi" 不匹配 "this")；
+// 中日韩文字没有词间空格，直接按子串匹配 ("你好" 匹配 "大家你好啊")
+func containsTrigger(text, trigger string) bool {
+	if trigger == "" {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(trigger)
+	last, _ := utf8.DecodeLastRuneInString(trigger)
+
+	for offset := 0; offset < len(text); {
+		idx := strings.Index(text[offset:], trigger)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(trigger)
+
+		boundary := true
+		if isSpacedWordRune(first) && start > 0 {
+			before, _ := utf8.DecodeLastRuneInString(text[:start])
+			boundary = !isSpacedWordRune(before)
+		}
+		if boundary && isSpacedWordRune(last) && end < len(text) {
+			after, _ := utf8.DecodeRuneInString(text[end:])
+			boundary = !isSpacedWordRune(after)
+		}
+		if boundary {
+			return true
+		}
+
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// splitAlternatives 按 %%% 拆分备选回复，每段保留各自的格式实体
+func splitAlternatives(text string, entities []MessageEntity) []FilterReply {
+	var replies []FilterReply
+	start := 0
+	for {
+		end := len(text)
+		idx := strings.Index(text[start:], filterAlternativeSeparator)
+		if idx >= 0 {
+			end = start + idx
+		}
+
+		part, partEntities := applyTextEdits(text, entities, []textEdit{
+			{start: 0, end: start},
+			{start: end, end: len(text)},
+		})
+		part, partEntities = trimEntityText(part, partEntities)
+		if part != "" {
+			replies = append(replies, FilterReply{Text: part, Entities: partEntities})
+		}
+
+		if idx < 0 {
+			break
+		}
+		start = end + len(filterAlternativeSeparator)
+	}
+	return replies
+}
+
+// fillPlaceholders 替换回复中的占位符并调整格式实体
+func fillPlaceholders(reply FilterReply, message *Message) FilterReply {
+	matches := filterPlaceholderPattern.FindAllStringSubmatchIndex(reply.Text, -1)
+	if len(matches) == 0 {
+		return reply
+	}
+
+	user := message.From
+	if user == nil {
+		user = &User{}
+	}
+
+	var edits []textEdit
+	for _, m := range matches {
+		var value string
+		switch reply.Text[m[2]:m[3]] {
+		case "first":
+			value = user.FirstName
+		case "last":
+			value = user.LastName
+		case "fullname":
+			value = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		case "username":
+			value = getUserName(user)
+		case "id":
+			value = fmt.Sprintf("%d", user.ID)
+		case "chatname":
+			value = message.Chat.Title
+		}
+		edits = append(edits, textEdit{start: m[0], end: m[1], replacement: value})
+	}
+
+	text, entities := applyTextEdits(reply.Text, reply.Entities, edits)
+	text, entities = trimEntityText(text, entities)
+	return FilterReply{Text: text, Entities: entities}
+}
+
+// checkFilters 检查消息是否命中自动回复，命中时随机选择一个备选内容回复
+func (h *MessageHandler) checkFilters(ctx context.Context, message *Message) {
+	if message.Chat.Type == "private" {
+		return
+	}
+
+	filter, ok := h.filters.Match(message.Chat.ID, messageText(message))
+	if !ok {
+		return
+	}
+
+	reply, ok := pickFilterReply(filter, message)
+	if !ok {
+		return
+	}
+
+	note := Note{
+		Type:     filter.Type,
+		FileID:   filter.FileID,
+		Text:     reply.Text,
+		Entities: reply.Entities,
+		Buttons:  filter.Buttons,
+	}
+	if err := h.sendNote(ctx, message.Chat.ID, note, message.MessageID); err != nil {
+		log.Printf("发送自动回复失败: %v", err)
+	}
+}
+
+// pickFilterReply 随机选择一个备选内容并替换占位符
+// 文本回复替换后为空时 (如 {last} 而用户没有姓氏) 改用其他备选，全部为空则不回复；媒体回复允许没有说明文字
+func pickFilterReply(filter Filter, message *Message) (FilterReply, bool) {
+	if len(filter.Replies) == 0 {
+		return FilterReply{}, filter.Type != noteTypeText
+	}
+
+	for _, i := range rand.Perm(len(filter.Replies)) {
+		reply := fillPlaceholders(filter.Replies[i], message)
+		if reply.Text != "" || filter.Type != noteTypeText {
+			return reply, true
+		}
+	}
+	return FilterReply{}, false
+}
+
+// parseFilterTrigger 解析 /filter 后的触发词，多个词的触发词用双引号括起
+// 返回触发词和其后的内容
+func parseFilterTrigger(rest string) (string, string, bool) {
+	if strings.HasPrefix(rest, `"`) {
+		end := strings.Index(rest[1:], `"`)
+		if end < 0 {
+			return "", "", false
+		}
+		return strings.TrimSpace(rest[1 : end+1]), strings.TrimSpace(rest[end+2:]), true
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", "", false
+	}
+	return fields[0], commandRemainder(rest, 1), true
+}
+
+// handleFilterCommand 处理 /filter <触发词> <回复> 命令，回复消息时以被回复的消息作为回复内容
+func (h *MessageHandler) handleFilterCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	usage := "❌ 用法: /filter <触发词> <回复>，或回复消息发送 /filter <触发词>\n" +
+		"多个词的触发词用双引号括起，如 /filter \"早上好\" 早安 {first}\n" +
+		"用 %%% 分隔多个备选回复，可用占位符: {first} {last} {fullname} {username} {id} {chatname}\n" +
+		"按钮语法: [文字](buttonurl://链接)，链接后加 :same 与上一个按钮同行"
+
+	trigger, content, ok := parseFilterTrigger(commandRemainder(message.Text, 1))
+	if !ok || trigger == "" {
+		return h.sendReply(ctx, message, usage)
+	}
+	if len([]rune(trigger)) > maxFilterTriggerLength {
+		return h.sendReply(ctx, message, fmt.Sprintf("❌ 触发词最多 %d 个字符", maxFilterTriggerLength))
+	}
+
+	// 回复内容与笔记使用相同的格式: 媒体 file_id、格式实体和链接按钮
+	var note Note
+	var err error
+	if content != "" {
+		start := len(strings.TrimRightFunc(message.Text, unicode.IsSpace)) - len(content)
+		text, entities := applyTextEdits(message.Text, message.Entities, []textEdit{{start: 0, end: start}})
+		note, err = noteFromMessage(&Message{Text: text, Entities: entities})
+	} else if message.ReplyToMessage != nil {
+		note, err = noteFromMessage(message.ReplyToMessage)
+	} else {
+		return h.sendReply(ctx, message, usage)
+	}
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 保存自动回复失败: "+err.Error())
+	}
+
+	replies := splitAlternatives(note.Text, note.Entities)
+	if note.Type == noteTypeText && len(replies) == 0 {
+		return h.sendReply(ctx, message, "❌ 保存自动回复失败: 回复内容不能为空")
+	}
+
+	filter := Filter{
+		Trigger:   trigger,
+		Type:      note.Type,
+		FileID:    note.FileID,
+		Replies:   replies,
+		Buttons:   note.Buttons,
+		CreatedAt: time.Now().Unix(),
+	}
+	if message.From != nil {
+		filter.CreatedBy = message.From.ID
+	}
+
+	replaced, err := h.filters.Save(message.Chat.ID, filter)
+	if err != nil {
+		log.Printf("保存自动回复失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存自动回复失败")
+	}
+
+	text := fmt.Sprintf("✅ 已添加自动回复: %s", trigger)
+	if replaced {
+		text = fmt.Sprintf("✅ 已更新自动回复: %s", trigger)
+	}
+	if len(replies) > 1 {
+		text += fmt.Sprintf(" (%d 个备选回复)", len(replies))
+	}
+	return h.sendReply(ctx, message, text)
+}
+
+// handleFiltersCommand 处理 /filters 命令，列出本群的自动回复
+func (h *MessageHandler) handleFiltersCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	filters := h.filters.List(message.Chat.ID)
+	if len(filters) == 0 {
+		return h.sendReply(ctx, message, "📭 本群还没有自动回复\n管理员可以发送 /filter <触发词> <回复> 添加")
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("💬 本群自动回复 (%d):\n\n", len(filters)))
+	for _, filter := range filters {
+		b.WriteString(fmt.Sprintf("• %s (%s", filter.Trigger, noteTypeLabels[filter.Type]))
+		if len(filter.Replies) > 1 {
+			b.WriteString(fmt.Sprintf("，%d 个备选", len(filter.Replies)))
+		}
+		b.WriteString(")\n")
+	}
+	b.WriteString("\n发送 /stop <触发词> 删除自动回复")
+
+	return h.sendReply(ctx, message, b.String())
+}
+
+// handleStopCommand 处理 /stop <触发词> 命令，删除自动回复
+func (h *MessageHandler) handleStopCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	trigger, _, ok := parseFilterTrigger(commandRemainder(message.Text, 1))
+	if !ok || trigger == "" {
+		return h.sendReply(ctx, message, "❌ 用法: /stop <触发词>")
+	}
+
+	if err := h.filters.Delete(message.Chat.ID, trigger); err != nil {
+		return h.sendReply(ctx, message, "❌ "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已删除自动回复: %s", trigger))
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContainsTrigger(t *testing.T) {
+	tests := []struct {
+		text    string
+		trigger string
+		want    bool
+	}{
+		{"hi", "hi", true},
+		{"hi there", "hi", true},
+		{"oh, hi!", "hi", true},
+		{"this is it", "hi", false},
+		{"xhello", "hello", false},
+		{"hello_world", "hello", false},
+		{"hello, world", "hello", true},
+		{"this hi", "hi", true},
+		{"大家你好啊", "你好", true},
+		{"ok了", "ok", true},
+		{"说hi吧", "hi", true},
+		{"good morning all", "good morning", true},
+		{"goodmorning", "good morning", false},
+		{"😀hi😀", "hi", true},
+		{"anything", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := containsTrigger(tt.text, tt.trigger); got != tt.want {
+			t.Errorf("containsTrigger(%q, %q) = %v, want %v", tt.text, tt.trigger, got, tt.want)
+		}
+	}
+}
+
+func TestSplitAlternatives(t *testing.T) {
+	text := "a%%%*b*%%%  %%%c"
+	entities := []MessageEntity{{Type: "bold", Offset: 4, Length: 3}}
+
+	got := splitAlternatives(text, entities)
+	want := []FilterReply{
+		{Text: "a"},
+		{Text: "*b*", Entities: []MessageEntity{{Type: "bold", Offset: 0, Length: 3}}},
+		{Text: "c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitAlternatives = %+v, want %+v", got, want)
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	message := &Message{
+		From: &User{ID: 42, FirstName: "小明"},
+		Chat: &Chat{Title: "测试群"},
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"你好 {first}，欢迎来到 {chatname}", "你好 小明，欢迎来到 测试群"},
+		{"{id}", "42"},
+		{"{fullname}", "小明"},
+		{"{last}", ""},
+		{" {last} ", ""},
+		{"{unknown}", "{unknown}"},
+	}
+
+	for _, tt := range tests {
+		got := fillPlaceholders(FilterReply{Text: tt.text}, message)
+		if got.Text != tt.want {
+			t.Errorf("fillPlaceholders(%q) = %q, want %q", tt.text, got.Text, tt.want)
+		}
+	}
+}
+
+func TestPickFilterReplySkipsEmptyText(t *testing.T) {
+	message := &Message{From: &User{ID: 1, FirstName: "A"}, Chat: &Chat{}}
+
+	filter := Filter{Type: noteTypeText, Replies: []FilterReply{{Text: "{last}"}, {Text: "hi {first}"}}}
+	for i := 0; i < 20; i++ {
+		reply, ok := pickFilterReply(filter, message)
+		if !ok || reply.Text != "hi A" {
+			t.Fatalf("pickFilterReply = %q, %v, want %q, true", reply.Text, ok, "hi A")
+		}
+	}
+
+	filter.Replies = []FilterReply{{Text: "{last}"}}
+	if _, ok := pickFilterReply(filter, message); ok {
+		t.Errorf("pickFilterReply with only empty text replies should not reply")
+	}
+
+	filter.Type = "photo"
+	if reply, ok := pickFilterReply(filter, message); !ok || reply.Text != "" {
+		t.Errorf("pickFilterReply for media = %q, %v, want empty caption, true", reply.Text, ok)
+	}
+}
@@ -0,0 +1,359 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxGlobalBanListShown /gbanlist 最多显示的条数
+const maxGlobalBanListShown = 50
+
+// GlobalBan 一条全局封禁记录
+type GlobalBan struct {
+	UserID   int64   `json:"user_id"`
+	Name     string  `json:"name,omitempty"`
+	Reason   string  `json:"reason,omitempty"`
+	BannedBy int64   `json:"banned_by"`
+	BannedAt int64   `json:"banned_at"`
+	Chats    []int64 `json:"chats,omitempty"` // 因全局封禁而封禁的聊天，解除时只在这些聊天中解封
+}
+
+// GlobalBanManager 管理由超级管理员设置的全局封禁列表
+type GlobalBanManager struct {
+	mu      sync.RWMutex
+	storage *Storage
+	bans    map[int64]*GlobalBan
+}
+
+// NewGlobalBanManager 创建全局封禁管理器并从存储中加载
+func NewGlobalBanManager(storage *Storage) (*GlobalBanManager, error) {
+	m := &GlobalBanManager{
+		storage: storage,
+		bans:    make(map[int64]*GlobalBan),
+	}
+
+	if err := storage.Load("gbans", &m.bans); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Get 获取用户的全局封禁记录
+func (m *GlobalBanManager) Get(userID int64) (GlobalBan, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ban, ok := m.bans[userID]
+	if !ok {
+		return GlobalBan{}, false
+	}
+	copied := *ban
+	copied.Chats = append([]int64(nil), ban.Chats...)
+	return copied, true
+}
+
+// Add 添加全局封禁，已存在时更新原因并保留已封禁的聊天
+func (m *GlobalBanManager) Add(ban GlobalBan) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.bans[ban.UserID]
+	if ok {
+		ban.Chats = append([]int64(nil), existing.Chats...)
+	}
+	m.bans[ban.UserID] = &ban
+
+	return m.commit(ban.UserID, existing, ok)
+}
+
+// Remove 移除全局封禁，返回被移除的记录
+func (m *GlobalBanManager) Remove(userID int64) (GlobalBan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ban, ok := m.bans[userID]
+	if !ok {
+		return GlobalBan{}, fmt.Errorf("该用户未被全局封禁")
+	}
+	delete(m.bans, userID)
+
+	if err := m.commit(userID, ban, true); err != nil {
+		return GlobalBan{}, err
+	}
+	return *ban, nil
+}
+
+// MarkEnforced 记录已在聊天中执行全局封禁
+func (m *GlobalBanManager) MarkEnforced(userID, chatID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ban, ok := m.bans[userID]
+	if !ok || containsInt64(ban.Chats, chatID) {
+		return nil
+	}
+	updated := *ban
+	updated.Chats = append(append([]int64(nil), ban.Chats...), chatID)
+	m.bans[userID] = &updated
+
+	return m.commit(userID, ban, true)
+}
+
+// commit 保存全局封禁列表，失败时恢复用户原来的记录 (existed 为 false 表示原来没有记录)
+// 调用方需持有写锁
+func (m *GlobalBanManager) commit(userID int64, previous *GlobalBan, existed bool) error {
+	if err := m.storage.Save("gbans", m.bans); err != nil {
+		if existed {
+			m.bans[userID] = previous
+		} else {
+			delete(m.bans, userID)
+		}
+		return err
+	}
+	return nil
+}
+
+// List 列出所有全局封禁 (按封禁时间排序)
+func (m *GlobalBanManager) List() []GlobalBan {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	bans := make([]GlobalBan, 0, len(m.bans))
+	for _, ban := range m.bans {
+		bans = append(bans, *ban)
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].BannedAt < bans[j].BannedAt
+	})
+	return bans
+}
+
+// administeredChats 返回Bot担任管理员、且未关闭全局封禁的群组
+func (h *MessageHandler) administeredChats() []ChatRecord {
+	var chats []ChatRecord
+	for _, record := range h.chatRegistry.List() {
+		if !record.IsActive() || record.Type == "private" || record.Type == "channel" {
+			continue
+		}
+		if record.Member == nil || record.Member.Status != MemberStatusAdministrator {
+			continue
+		}
+		if h.settings.Get(record.ChatID).GlobalBanOptOut {
+			continue
+		}
+		chats = append(chats, record)
+	}
+	return chats
+}
+
+// handleGlobalBanCommand 处理 /gban <用户ID> [原因] 命令，仅超级管理员可用
+func (h *MessageHandler) handleGlobalBanCommand(ctx context.Context, message *Message, args []string) error {
+	if message.From == nil || !h.isSuperAdmin(message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 此命令仅限超级管理员使用")
+	}
+
+	userID, target, rest, ok := parseTargetUser(message, args)
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 用法: /gban <用户ID> [原因]，或回复用户的消息发送 /gban [原因]")
+	}
+	if h.isSuperAdmin(userID) {
+		return h.sendReply(ctx, message, "❌ 不能全局封禁超级管理员")
+	}
+	if h.botUser != nil && userID == h.botUser.ID {
+		return h.sendReply(ctx, message, "❌ 不能封禁Bot")
+	}
+
+	ban := GlobalBan{
+		UserID:   userID,
+		Reason:   strings.Join(rest, " "),
+		BannedBy: message.From.ID,
+		BannedAt: time.Now().Unix(),
+	}
+	if target != nil {
+		ban.Name = getUserName(target)
+	}
+
+	if err := h.gbans.Add(ban); err != nil {
+		log.Printf("保存全局封禁失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存全局封禁失败")
+	}
+
+	chats := h.administeredChats()
+	banned := 0
+	for _, record := range chats {
+		if h.applyGlobalBan(ctx, &Chat{ID: record.ChatID, Title: record.Title, Type: record.Type}, userID, target, ban, message.From) {
+			banned++
+		}
+	}
+
+	name := fmt.Sprintf("%d", userID)
+	if target != nil {
+		name = formatUser(target)
+	}
+	return h.sendReply(ctx, message, fmt.Sprintf("🌐 已全局封禁 %s\n原因: %s\n已在 %d/%d 个群组中执行",
+		name, banReason(ban.Reason), banned, len(chats)))
+}
+
+// applyGlobalBan 在聊天中执行全局封禁并记录，返回是否成功
+func (h *MessageHandler) applyGlobalBan(ctx context.Context, chat *Chat, userID int64, target *User, ban GlobalBan, actor *User) bool {
+	if err := h.client.BanChatMember(ctx, BanChatMemberParams{ChatID: chat.ID, UserID: userID}); err != nil {
+		log.Printf("在群组 %d 执行全局封禁失败: %v", chat.ID, err)
+		return false
+	}
+
+	if err := h.gbans.MarkEnforced(userID, chat.ID); err != nil {
+		log.Printf("保存全局封禁失败: %v", err)
+	}
+
+	h.logModAction(ctx, ModAction{
+		Action:   ModActionGlobalBan,
+		Chat:     chat,
+		Actor:    actor,
+		TargetID: userID,
+		Target:   target,
+		Reason:   banReason(ban.Reason),
+	})
+	return true
+}
+
+// handleGlobalUnbanCommand 处理 /ungban <用户ID> 命令，仅超级管理员可用
+func (h *MessageHandler) handleGlobalUnbanCommand(ctx context.Context, message *Message, args []string) error {
+	if message.From == nil || !h.isSuperAdmin(message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 此命令仅限超级管理员使用")
+	}
+
+	userID, target, _, ok := parseTargetUser(message, args)
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 用法: /ungban <用户ID>")
+	}
+
+	ban, err := h.gbans.Remove(userID)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ "+err.Error())
+	}
+
+	// 只在因全局封禁而封禁的聊天中解封，不影响群组自行设置的封禁
+	unbanned := 0
+	for _, chatID := range ban.Chats {
+		err := h.client.UnbanChatMember(ctx, UnbanChatMemberParams{ChatID: chatID, UserID: userID, OnlyIfBanned: true})
+		if err != nil {
+			log.Printf("在群组 %d 解除全局封禁失败: %v", chatID, err)
+			continue
+		}
+		unbanned++
+
+		h.logModAction(ctx, ModAction{
+			Action:   ModActionGlobalUnban,
+			Chat:     h.registeredChat(chatID),
+			Actor:    message.From,
+			TargetID: userID,
+			Target:   target,
+		})
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已解除 %d 的全局封禁\n已在 %d/%d 个群组中解封", userID, unbanned, len(ban.Chats)))
+}
+
+// handleGlobalBanListCommand 处理 /gbanlist 命令，仅超级管理员可用
+func (h *MessageHandler) handleGlobalBanListCommand(ctx context.Context, message *Message) error {
+	if message.From == nil || !h.isSuperAdmin(message.From.ID) {
+		return h.sendReply(ctx, message, "❌ 此命令仅限超级管理员使用")
+	}
+
+	bans := h.gbans.List()
+	if len(bans) == 0 {
+		return h.sendReply(ctx, message, "📭 没有全局封禁记录")
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🌐 全局封禁列表 (共 %d 人)\n\n", len(bans)))
+	for i, ban := range bans {
+		if i == maxGlobalBanListShown {
+			b.WriteString(fmt.Sprintf("\n... 仅显示前 %d 条", maxGlobalBanListShown))
+			break
+		}
+		name := ""
+		if ban.Name != "" {
+			name = " " + ban.Name
+		}
+		b.WriteString(fmt.Sprintf("%d.%s (%d) - %s [%s]\n", i+1, name, ban.UserID, banReason(ban.Reason),
+			time.Unix(ban.BannedAt, 0).Format("2006-01-02 15:04")))
+	}
+
+	return h.sendReply(ctx, message, b.String())
+}
+
+// handleGlobalBansCommand 处理 /gbans on|off 命令，设置本群是否执行全局封禁
+func (h *MessageHandler) handleGlobalBansCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	if len(args) == 0 {
+		optOut := h.settings.Get(message.Chat.ID).GlobalBanOptOut
+		return h.sendReply(ctx, message, fmt.Sprintf("🌐 全局封禁: %s\n用法: /gbans on|off", onOff(!optOut)))
+	}
+
+	var optOut bool
+	switch strings.ToLower(args[0]) {
+	case "on":
+		optOut = false
+	case "off":
+		optOut = true
+	default:
+		return h.sendReply(ctx, message, "❌ 用法: /gbans on|off")
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		s.GlobalBanOptOut = optOut
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	if optOut {
+		return h.sendReply(ctx, message, "✅ 本群已关闭全局封禁，全局封禁的用户不会在本群被自动封禁")
+	}
+	return h.sendReply(ctx, message, "✅ 本群已开启全局封禁")
+}
+
+// checkGlobalBan 全局封禁的用户入群或发言时自动封禁
+// 返回 true 表示消息发送者 (或所有新成员) 已被封禁，不再处理该消息
+func (h *MessageHandler) checkGlobalBan(ctx context.Context, message *Message) bool {
+	if message.Chat.Type == "private" || h.settings.Get(message.Chat.ID).GlobalBanOptOut {
+		return false
+	}
+	return h.enforceBanList(ctx, message, h.enforceGlobalBan)
+}
+
+// enforceGlobalBan 用户被全局封禁时将其封禁，返回是否已封禁
+func (h *MessageHandler) enforceGlobalBan(ctx context.Context, chat *Chat, user *User) bool {
+	ban, ok := h.gbans.Get(user.ID)
+	if !ok {
+		return false
+	}
+
+	if !h.applyGlobalBan(ctx, chat, user.ID, user, ban, h.botUser) {
+		return false
+	}
+
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: chat.ID,
+		Text:   fmt.Sprintf("🌐 %s 已被全局封禁，已自动移出\n原因: %s", getUserName(user), banReason(ban.Reason)),
+	})
+	if err != nil {
+		log.Printf("发送全局封禁通知失败: %v", err)
+	}
+	return true
+}
@@ -6,18 +6,136 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // MessageHandler 消息处理器
 type MessageHandler struct {
-	client *ApiClient
+	client      *ApiClient
+	settings    *SettingsManager
+	rules       *RuleManager
+	albums      *mediaGroupCollector
+	mirrors     *MirrorManager
+	logBinder   *logChannelBinder
+	blacklist   *BlacklistManager
+	invites     *InviteManager
+	federations *FederationManager
+	gbans       *GlobalBanManager
+	notes       *NoteManager
+	filters     *FilterManager
+
+	mirrorAlbums *mediaGroupCollector
+	chatRegistry *ChatRegistry
+	admins       *adminCache
+	anonRequests *anonAdminRequests
+	promotions   *promoteRequests
+	reports      *reportTracker
+	flood        *floodDetector
+	recent       *messageTracker
+	slowMode     *slowModeTracker
+	scheduler    *scheduler
+	nightModeMu  sync.Mutex
+	botUser      *User
+
+	defaultForwardTarget  int64
+	superAdmins           []int64
+	allowedChats          []int64
+	autoLeaveUnauthorized bool
 }
 
 // NewMessageHandler 创建新的消息处理器
-func NewMessageHandler(client *ApiClient) *MessageHandler {
-	return &MessageHandler{
-		client: client,
+func NewMessageHandler(client *ApiClient, storage *Storage, opts Options) (*MessageHandler, error) {
+	settings, err := NewSettingsManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载群组配置失败: %w", err)
+	}
+
+	rules, err := NewRuleManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载转发规则失败: %w", err)
+	}
+
+	mirrors, err := NewMirrorManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载频道镜像配置失败: %w", err)
+	}
+
+	chatRegistry, err := NewChatRegistry(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载聊天登记失败: %w", err)
+	}
+
+	blacklist, err := NewBlacklistManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载黑名单失败: %w", err)
+	}
+
+	invites, err := NewInviteManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载邀请链接统计失败: %w", err)
+	}
+
+	federations, err := NewFederationManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载联邦失败: %w", err)
+	}
+
+	gbans, err := NewGlobalBanManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载全局封禁列表失败: %w", err)
+	}
+
+	notes, err := NewNoteManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载笔记失败: %w", err)
 	}
+
+	filters, err := NewFilterManager(storage)
+	if err != nil {
+		return nil, fmt.Errorf("加载自动回复失败: %w", err)
+	}
+
+	h := &MessageHandler{
+		client:                client,
+		settings:              settings,
+		rules:                 rules,
+		mirrors:               mirrors,
+		logBinder:             newLogChannelBinder(),
+		blacklist:             blacklist,
+		invites:               invites,
+		federations:           federations,
+		gbans:                 gbans,
+		notes:                 notes,
+		filters:               filters,
+		chatRegistry:          chatRegistry,
+		admins:                newAdminCache(adminCacheTTL),
+		anonRequests:          newAnonAdminRequests(),
+		promotions:            newPromoteRequests(),
+		reports:               newReportTracker(),
+		flood:                 newFloodDetector(),
+		recent:                newMessageTracker(),
+		slowMode:              newSlowModeTracker(),
+		scheduler:             newScheduler(),
+		defaultForwardTarget:  opts.DefaultForwardTarget,
+		superAdmins:           opts.SuperAdmins,
+		allowedChats:          opts.AllowedChats,
+		autoLeaveUnauthorized: opts.AutoLeaveUnauthorized,
+	}
+	h.albums = newMediaGroupCollector(mediaGroupWait, h.applyForwardRulesToAlbum)
+	h.mirrorAlbums = newMediaGroupCollector(mediaGroupWait, h.mirrorAlbum)
+
+	h.scheduler.every("nightmode", nightModeCheckInterval, h.checkNightModes)
+	h.scheduler.every("slowmode-prune", 10*time.Minute, h.slowMode.prune)
+	h.scheduler.every("report-prune", reportPruneEvery, h.reports.prune)
+	h.scheduler.every("mirror-flush", mirrorFlushInterval, h.flushMirrorRecords)
+
+	return h, nil
+}
+
+// SetBotUser 记录Bot自身的用户信息 (用于检查Bot在群组中的权限)
+func (h *MessageHandler) SetBotUser(user *User) {
+	h.botUser = user
 }
 
 // HandleMessage 处理普通消息
@@ -29,8 +147,35 @@ func (h *MessageHandler) HandleMessage(ctx context.Context, message *Message) er
 
 	log.Printf("收到消息: [%s] %s: %s", message.Chat.Type, getUserName(message.From), message.Text)
 
+	h.trackMessage(message)
+
+	// 全局封禁或联邦封禁的用户入群或发言时直接封禁
+	if h.checkGlobalBan(ctx, message) || h.checkFedBan(ctx, message) {
+		return nil
+	}
+
+	// 新成员入群
+	if len(message.NewChatMembers) > 0 {
+		h.checkBotsLock(ctx, message)
+		return h.handleNewChatMembers(ctx, message)
+	}
+
+	// 防刷屏检查，触发后不再处理该消息
+	if h.checkFlood(ctx, message) {
+		return nil
+	}
+
+	// 慢速模式检查，发送过快的消息已被删除
+	if h.checkSlowMode(ctx, message) {
+		return nil
+	}
+
 	// 检查是否为命令
 	if strings.HasPrefix(message.Text, "/") {
+		// 以 / 开头的消息同样要检查黑名单和锁定，防止借命令格式绕过
+		if h.checkBlacklist(ctx, message) || h.checkLocks(ctx, message) {
+			return nil
+		}
 		return h.handleCommand(ctx, message)
 	}
 
@@ -45,9 +190,46 @@ func (h *MessageHandler) HandleEditedMessage(ctx context.Context, message *Messa
 	}
 
 	log.Printf("收到编辑消息: [%s] %s: %s", message.Chat.Type, getUserName(message.From), message.Text)
-	
-	// 对于编辑的消息，暂时只记录日志
-	// 后续可以根据需要添加特殊处理逻辑
+
+	// 编辑后的内容同样需要检查黑名单和锁定，防止先发普通消息再编辑加入链接或提及
+	if !h.checkBlacklist(ctx, message) {
+		h.checkLocks(ctx, message)
+	}
+	return nil
+}
+
+// HandleChannelPost 处理频道消息
+func (h *MessageHandler) HandleChannelPost(ctx context.Context, post *Message) error {
+	if post == nil {
+		return nil
+	}
+
+	log.Printf("收到频道消息: [%s] %s", post.Chat.Title, post.Text)
+
+	if parts := strings.Fields(post.Text); len(parts) > 0 {
+		switch strings.ToLower(parts[0]) {
+		case "/setlog":
+			// 在频道中发送 /setlog 开始绑定日志频道
+			return h.handleChannelSetLog(ctx, post)
+		case "/delete":
+			// 回复频道消息发送 /delete 删除该消息及其镜像
+			return h.handleChannelDelete(ctx, post)
+		}
+	}
+
+	h.mirrorChannelPost(ctx, post)
+	return nil
+}
+
+// HandleEditedChannelPost 处理编辑的频道消息
+func (h *MessageHandler) HandleEditedChannelPost(ctx context.Context, post *Message) error {
+	if post == nil {
+		return nil
+	}
+
+	log.Printf("收到编辑的频道消息: [%s] %s", post.Chat.Title, post.Text)
+
+	h.propagateMirrorEdit(ctx, post)
 	return nil
 }
 
@@ -58,10 +240,26 @@ func (h *MessageHandler) HandleCallbackQuery(ctx context.Context, query *Callbac
 	}
 
 	log.Printf("收到回调查询: %s 点击了 %s", getUserName(query.From), query.Data)
-	
-	// 这里可以根据callback_data处理不同的按钮点击
-	// 暂时只记录日志
-	return nil
+
+	// 根据callback_data前缀分发到对应的功能
+	switch {
+	case strings.HasPrefix(query.Data, settingsCallbackPrefix):
+		return h.handleSettingsCallback(ctx, query, strings.TrimPrefix(query.Data, settingsCallbackPrefix))
+	case strings.HasPrefix(query.Data, rulesCallbackPrefix):
+		return h.handleRulesCallback(ctx, query, strings.TrimPrefix(query.Data, rulesCallbackPrefix))
+	case strings.HasPrefix(query.Data, anonAdminCallbackPrefix):
+		return h.handleAnonAdminCallback(ctx, query, strings.TrimPrefix(query.Data, anonAdminCallbackPrefix))
+	case strings.HasPrefix(query.Data, promoteCallbackPrefix):
+		return h.handlePromoteCallback(ctx, query, strings.TrimPrefix(query.Data, promoteCallbackPrefix))
+	case strings.HasPrefix(query.Data, reportCallbackPrefix):
+		return h.handleReportCallback(ctx, query, strings.TrimPrefix(query.Data, reportCallbackPrefix))
+	case strings.HasPrefix(query.Data, unpinAllCallbackPrefix):
+		return h.handleUnpinAllCallback(ctx, query, strings.TrimPrefix(query.Data, unpinAllCallbackPrefix))
+	case strings.HasPrefix(query.Data, modLogCallbackPrefix):
+		return h.handleModLogCallback(ctx, query, strings.TrimPrefix(query.Data, modLogCallbackPrefix))
+	default:
+		return h.answerCallback(ctx, query, "", false)
+	}
 }
 
 // HandleChatJoinRequest 处理加群请求
@@ -71,9 +269,118 @@ func (h *MessageHandler) HandleChatJoinRequest(ctx context.Context, request *Cha
 	}
 
 	log.Printf("收到加群请求: %s 想加入 %s", getUserName(request.From), request.Chat.Title)
-	
-	// 这里可以实现自动审批逻辑
-	// 暂时只记录日志
+
+	// 统计加群申请使用的邀请链接
+	h.trackJoinRequest(request)
+	return nil
+}
+
+// HandleInlineQuery 处理内联查询
+func (h *MessageHandler) HandleInlineQuery(ctx context.Context, query *InlineQuery) error {
+	if query == nil {
+		return nil
+	}
+
+	log.Printf("收到内联查询: %s 查询 %q", getUserName(query.From), query.Query)
+	return nil
+}
+
+// HandleChosenInlineResult 处理用户选择的内联结果
+func (h *MessageHandler) HandleChosenInlineResult(ctx context.Context, result *ChosenInlineResult) error {
+	if result == nil {
+		return nil
+	}
+
+	log.Printf("收到内联结果选择: %s 选择了 %s", getUserName(result.From), result.ResultID)
+	return nil
+}
+
+// HandleShippingQuery 处理配送查询
+func (h *MessageHandler) HandleShippingQuery(ctx context.Context, query *ShippingQuery) error {
+	if query == nil {
+		return nil
+	}
+
+	log.Printf("收到配送查询: %s (%s)", getUserName(query.From), query.InvoicePayload)
+	return nil
+}
+
+// HandlePreCheckoutQuery 处理支付前确认
+func (h *MessageHandler) HandlePreCheckoutQuery(ctx context.Context, query *PreCheckoutQuery) error {
+	if query == nil {
+		return nil
+	}
+
+	log.Printf("收到支付确认: %s %d %s (%s)", getUserName(query.From), query.TotalAmount, query.Currency, query.InvoicePayload)
+	return nil
+}
+
+// HandlePoll 处理投票状态变更
+func (h *MessageHandler) HandlePoll(ctx context.Context, poll *Poll) error {
+	if poll == nil {
+		return nil
+	}
+
+	log.Printf("收到投票更新: %s (共 %d 人投票)", poll.Question, poll.TotalVoterCount)
+	return nil
+}
+
+// HandlePollAnswer 处理投票答案
+func (h *MessageHandler) HandlePollAnswer(ctx context.Context, answer *PollAnswer) error {
+	if answer == nil {
+		return nil
+	}
+
+	log.Printf("收到投票答案: %s 在投票 %s 中选择了 %v", getUserName(answer.User), answer.PollID, answer.OptionIDs)
+	return nil
+}
+
+// HandleMyChatMember 处理Bot自身在聊天中的成员状态变更
+func (h *MessageHandler) HandleMyChatMember(ctx context.Context, update *ChatMemberUpdated) error {
+	if update == nil {
+		return nil
+	}
+
+	log.Printf("Bot在 %s 中的状态变更: %s -> %s (操作人: %s)",
+		update.Chat.Title, update.OldChatMember.Status, update.NewChatMember.Status, getUserName(update.From))
+
+	// Bot自身的权限可能变化，刷新管理员缓存
+	h.admins.invalidate(update.Chat.ID)
+
+	return h.trackMyChatMember(ctx, update)
+}
+
+// HandleChatMember 处理聊天成员状态变更
+func (h *MessageHandler) HandleChatMember(ctx context.Context, update *ChatMemberUpdated) error {
+	if update == nil {
+		return nil
+	}
+
+	log.Printf("成员状态变更: [%s] %s: %s -> %s",
+		update.Chat.Title, getUserName(update.NewChatMember.User), update.OldChatMember.Status, update.NewChatMember.Status)
+
+	h.invalidateAdminsOnChange(update)
+	h.trackChatMemberJoin(update)
+	return nil
+}
+
+// HandleMessageReaction 处理表情回应变更
+func (h *MessageHandler) HandleMessageReaction(ctx context.Context, reaction *MessageReactionUpdated) error {
+	if reaction == nil {
+		return nil
+	}
+
+	log.Printf("收到表情回应: [%s] %s 回应了消息 %d", reaction.Chat.Title, getUserName(reaction.User), reaction.MessageID)
+	return nil
+}
+
+// HandleMessageReactionCount 处理匿名表情回应数量变更
+func (h *MessageHandler) HandleMessageReactionCount(ctx context.Context, count *MessageReactionCountUpdated) error {
+	if count == nil {
+		return nil
+	}
+
+	log.Printf("收到表情回应统计: [%s] 消息 %d 共 %d 种回应", count.Chat.Title, count.MessageID, len(count.Reactions))
 	return nil
 }
 
@@ -101,8 +408,134 @@ func (h *MessageHandler) handleCommand(ctx context.Context, message *Message) er
 		return h.handleBanCommand(ctx, message, args)
 	case "/promote":
 		return h.handlePromoteCommand(ctx, message, args)
+	case "/demote":
+		return h.handleDemoteCommand(ctx, message, args)
 	case "/admins":
 		return h.handleAdminsCommand(ctx, message)
+	case "/report":
+		return h.handleReportCommand(ctx, message)
+	case "/reports":
+		return h.handleReportsCommand(ctx, message, args)
+	case "/admincache":
+		return h.handleAdminCacheCommand(ctx, message)
+	case "/newfed":
+		return h.handleNewFedCommand(ctx, message)
+	case "/delfed":
+		return h.handleDelFedCommand(ctx, message)
+	case "/joinfed":
+		return h.handleJoinFedCommand(ctx, message, args)
+	case "/leavefed":
+		return h.handleLeaveFedCommand(ctx, message)
+	case "/fedinfo":
+		return h.handleFedInfoCommand(ctx, message)
+	case "/fpromote":
+		return h.handleFedAdminCommand(ctx, message, args, true)
+	case "/fdemote":
+		return h.handleFedAdminCommand(ctx, message, args, false)
+	case "/fban":
+		return h.handleFedBanCommand(ctx, message, args)
+	case "/unfban":
+		return h.handleFedUnbanCommand(ctx, message, args)
+	case "/fbanlist":
+		return h.handleFedBanListCommand(ctx, message)
+	case "/fexport":
+		return h.handleFedExportCommand(ctx, message)
+	case "/fimport":
+		return h.handleFedImportCommand(ctx, message)
+	case "/settings":
+		return h.handleSettingsCommand(ctx, message, args)
+	case "/setflood":
+		return h.handleSetFloodCommand(ctx, message, args)
+	case "/blacklist":
+		return h.handleBlacklistCommand(ctx, message, args)
+	case "/lock":
+		return h.handleLockCommand(ctx, message, args, true)
+	case "/unlock":
+		return h.handleLockCommand(ctx, message, args, false)
+	case "/locks":
+		return h.handleLocksCommand(ctx, message, args)
+	case "/purge":
+		return h.handlePurgeCommand(ctx, message)
+	case "/del":
+		return h.handleDelCommand(ctx, message)
+	case "/purgeuser":
+		return h.handlePurgeUserCommand(ctx, message, args)
+	case "/pin":
+		return h.handlePinCommand(ctx, message, args)
+	case "/unpin":
+		return h.handleUnpinCommand(ctx, message)
+	case "/unpinall":
+		return h.handleUnpinAllCommand(ctx, message)
+	case "/pinned":
+		return h.handlePinnedCommand(ctx, message)
+	case "/antichannelpin":
+		return h.handleAntiChannelPinCommand(ctx, message, args)
+	case "/permissions":
+		return h.handlePermissionsCommand(ctx, message)
+	case "/setperm":
+		return h.handleSetPermCommand(ctx, message, args)
+	case "/readonly":
+		return h.handleReadOnlyCommand(ctx, message, args)
+	case "/nightmode":
+		return h.handleNightModeCommand(ctx, message, args)
+	case "/slowmode":
+		return h.handleSlowModeCommand(ctx, message, args)
+	case "/invitelink":
+		return h.handleInviteLinkCommand(ctx, message)
+	case "/newlink":
+		return h.handleNewLinkCommand(ctx, message, args)
+	case "/links":
+		return h.handleLinksCommand(ctx, message)
+	case "/revoke":
+		return h.handleRevokeCommand(ctx, message, args)
+	case "/settitle":
+		return h.handleSetTitleCommand(ctx, message)
+	case "/setdesc":
+		return h.handleSetDescCommand(ctx, message)
+	case "/setphoto":
+		return h.handleSetPhotoCommand(ctx, message)
+	case "/delphoto":
+		return h.handleDelPhotoCommand(ctx, message)
+	case "/title":
+		return h.handleTitleCommand(ctx, message, args)
+	case "/setlog":
+		return h.handleSetLogCommand(ctx, message, args)
+	case "/unsetlog":
+		return h.handleUnsetLogCommand(ctx, message)
+	case "/addrule":
+		return h.handleAddRuleCommand(ctx, message)
+	case "/rules":
+		return h.handleRulesCommand(ctx, message)
+	case "/delrule":
+		return h.handleDelRuleCommand(ctx, message, args)
+	case "/mirror":
+		return h.handleMirrorCommand(ctx, message, args)
+	case "/chats":
+		return h.handleChatsCommand(ctx, message)
+	case "/gban":
+		return h.handleGlobalBanCommand(ctx, message, args)
+	case "/ungban":
+		return h.handleGlobalUnbanCommand(ctx, message, args)
+	case "/gbanlist":
+		return h.handleGlobalBanListCommand(ctx, message)
+	case "/gbans":
+		return h.handleGlobalBansCommand(ctx, message, args)
+	case "/save":
+		return h.handleSaveCommand(ctx, message, args)
+	case "/get":
+		return h.handleGetCommand(ctx, message, args)
+	case "/notes":
+		return h.handleNotesCommand(ctx, message)
+	case "/clear":
+		return h.handleClearCommand(ctx, message, args)
+	case "/privatenotes":
+		return h.handlePrivateNotesCommand(ctx, message, args)
+	case "/filter":
+		return h.handleFilterCommand(ctx, message)
+	case "/filters":
+		return h.handleFiltersCommand(ctx, message)
+	case "/stop":
+		return h.handleStopCommand(ctx, message)
 	default:
 		return h.handleUnknownCommand(ctx, message, command)
 	}
@@ -110,8 +543,54 @@ func (h *MessageHandler) handleCommand(ctx context.Context, message *Message) er
 
 // handleNormalMessage 处理普通消息
 func (h *MessageHandler) handleNormalMessage(ctx context.Context, message *Message) error {
-	// 这里可以实现自动转发或其他逻辑
-	// 暂时只记录日志
+	// 关联频道自动转发的帖子按需取消置顶
+	h.checkChannelPin(ctx, message)
+
+	// 命中黑名单的消息已被删除，不再转发
+	if h.checkBlacklist(ctx, message) {
+		return nil
+	}
+
+	// 违反锁定设置的消息已被删除，不再转发
+	if h.checkLocks(ctx, message) {
+		return nil
+	}
+
+	// 回复消息并 @admin 视为举报
+	h.checkAdminMention(ctx, message)
+
+	// #名称 调用笔记，已调用笔记的消息不再触发自动回复
+	if !h.checkNoteHashtag(ctx, message) {
+		h.checkFilters(ctx, message)
+	}
+
+	// 按转发规则自动转发
+	h.applyForwardRules(ctx, message)
+	return nil
+}
+
+// handleNewChatMembers 处理新成员入群
+func (h *MessageHandler) handleNewChatMembers(ctx context.Context, message *Message) error {
+	// 通过审批入群的成员按其申请时使用的邀请链接统计
+	for _, member := range message.NewChatMembers {
+		h.trackInviteJoin(message.Chat.ID, member.ID, "")
+	}
+
+	if !h.settings.Get(message.Chat.ID).WelcomeEnabled {
+		return nil
+	}
+
+	for _, member := range message.NewChatMembers {
+		if member.IsBot {
+			continue
+		}
+
+		welcomeText := fmt.Sprintf("👋 欢迎 %s 加入 %s！", getUserName(&member), message.Chat.Title)
+		if err := h.sendReply(ctx, message, welcomeText); err != nil {
+			log.Printf("发送欢迎消息失败: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -136,14 +615,84 @@ func (h *MessageHandler) handleHelpCommand(ctx context.Context, message *Message
 /start - 开始使用Bot
 /help - 显示帮助信息
 /info - 获取群组信息
+/report [原因] - 回复消息向管理员举报 (也可回复消息并 @admin)
 
 📤 转发功能:
-/forward <目标群ID> - 转发回复的消息到指定群组
+/forward [目标群ID] - 转发回复的消息到指定群组 (不指定时使用默认转发目标)
+/addrule to=<群组ID> [条件...] - 添加自动转发规则
+/rules - 查看并启用/停用转发规则
+/delrule <编号> - 删除转发规则
+/mirror add|remove|delete|list - 管理频道镜像
 
 👮‍♂️ 管理命令 (仅管理员):
-/ban <@用户名> [原因] - 禁言用户
-/promote <@用户名> - 提升用户为管理员
+/ban <@用户名> [时长] [原因] - 禁言用户 (时长如 30m、2h、7d)
+/promote <用户ID> [mod|full|custom|权限...] - 提升管理员，确认前可勾选权限
+/demote <用户ID> - 撤销管理员 (也可回复消息)
 /admins - 查看管理员列表
+/admincache - 刷新管理员缓存
+/reports on|off - 开关成员举报功能
+/gbans on|off - 本群是否执行全局封禁
+/settings - 打开群组设置菜单
+/setflood <消息数> <秒数> [动作] [禁言时长] - 设置防刷屏 (/setflood off 关闭)
+/blacklist add|remove|list|action - 管理黑名单词条
+/lock <类型...> - 锁定内容类型 (url、mention、forward、photo 等)
+/unlock <类型...> - 解除锁定
+/locks - 查看锁定状态 (/locks allow <域名> 添加链接白名单)
+/purge - 回复消息，删除从该消息到命令之间的所有消息
+/del - 回复消息，删除该消息
+/purgeuser <用户ID> <条数> - 删除用户最近的消息
+/pin [loud] - 回复消息将其置顶 (loud 时通知所有成员)
+/unpin - 取消置顶回复的消息 (不回复时取消最近一条)
+/unpinall - 取消所有置顶消息
+/pinned - 查看当前置顶消息
+/antichannelpin on|off - 自动取消关联频道帖子的置顶
+/permissions - 查看成员默认权限
+/setperm <权限> on|off - 修改成员默认权限
+/readonly on|off - 开启或关闭只读模式
+/nightmode on <开始> <结束> [时区] - 定时夜间模式 (/nightmode off 关闭)
+/slowmode <秒数|off> - 慢速模式，限制成员发言间隔
+/invitelink - 生成新的主邀请链接
+/newlink <名称> [有效期] [人数上限] [request] - 创建附加邀请链接
+/links - 查看邀请链接及入群统计
+/revoke <链接> - 撤销邀请链接
+/settitle <名称> - 修改群组名称
+/setdesc [描述] - 修改群组描述 (不带参数时清除)
+/setphoto - 回复图片，将其设为群组头像
+/delphoto - 删除群组头像
+/title <用户ID> <头衔> - 设置管理员头衔 (也可回复消息)
+/setlog <绑定码> - 绑定管理日志频道 (先在频道中发送 /setlog)
+/unsetlog - 解除日志频道绑定
+
+📒 笔记命令:
+/save <名称> [内容] - 保存笔记 (可回复文字、图片、文件或视频，支持按钮)
+/get <名称> - 调用笔记 (也可直接发送 #名称)
+/notes - 查看本群笔记
+/clear <名称> - 删除笔记 (仅管理员)
+/privatenotes on|off - 笔记是否私聊发送给请求者 (仅管理员)
+
+💬 自动回复:
+/filter <触发词> <回复> - 添加关键词自动回复 (也可回复消息，支持媒体和按钮)
+/filters - 查看本群自动回复
+/stop <触发词> - 删除自动回复 (仅管理员)
+
+🏛 联邦命令:
+/newfed <名称> - 创建联邦
+/delfed - 删除自己的联邦
+/joinfed <联邦ID> - 群主将群组加入联邦
+/leavefed - 群主将群组退出联邦
+/fedinfo - 查看联邦信息
+/fpromote <用户ID> - 添加联邦管理员 (/fdemote 移除)
+/fban <用户ID> [原因] - 在联邦所有群组中封禁用户
+/unfban <用户ID> - 解除联邦封禁
+/fbanlist - 查看联邦封禁列表
+/fexport - 导出联邦封禁列表
+/fimport - 回复导出的文件，导入联邦封禁列表
+
+🛠 超级管理员命令:
+/chats - 查看Bot所在的所有聊天 (私聊)
+/gban <用户ID> [原因] - 在Bot管理的所有群组中封禁用户
+/ungban <用户ID> - 解除全局封禁
+/gbanlist - 查看全局封禁列表
 
 💡 使用提示：
 • 大部分管理命令需要管理员权限
@@ -168,6 +717,12 @@ func (h *MessageHandler) handleInfoCommand(ctx context.Context, message *Message
 		chat.ID,
 		chat.Type)
 
+	if count, err := h.client.GetChatMemberCount(ctx, chat.ID); err == nil {
+		infoText += fmt.Sprintf("\n👥 成员数: %d", count)
+	} else {
+		log.Printf("获取成员数量失败: %v", err)
+	}
+
 	if chat.Description != "" {
 		infoText += fmt.Sprintf("\n📄 描述: %s", chat.Description)
 	}
@@ -185,25 +740,40 @@ func (h *MessageHandler) handleForwardCommand(ctx context.Context, message *Mess
 		return h.sendReply(ctx, message, "❌ 请回复要转发的消息使用此命令")
 	}
 
-	if len(args) == 0 {
-		return h.sendReply(ctx, message, "❌ 请指定目标群组ID\n用法: /forward <群组ID>")
+	// 未指定目标时使用群组设置中的默认转发目标
+	var targets []int64
+	if len(args) > 0 {
+		targetChatID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return h.sendReply(ctx, message, "❌ 无效的群组ID")
+		}
+		targets = append(targets, targetChatID)
+	} else {
+		settings := h.settings.Get(message.Chat.ID)
+		if !settings.IsModuleEnabled(ModuleForward) {
+			return h.sendReply(ctx, message, "❌ 本群已关闭转发功能")
+		}
+		targets = settings.ForwardTargets
+		if len(targets) == 0 && h.defaultForwardTarget != 0 {
+			targets = []int64{h.defaultForwardTarget}
+		}
 	}
 
-	targetChatID, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		return h.sendReply(ctx, message, "❌ 无效的群组ID")
+	if len(targets) == 0 {
+		return h.sendReply(ctx, message, "❌ 请指定目标群组ID\n用法: /forward <群组ID>")
 	}
 
 	// 转发消息
-	params := ForwardMessageParams{
-		ChatID:     targetChatID,
-		FromChatID: message.Chat.ID,
-		MessageID:  message.ReplyToMessage.MessageID,
-	}
+	for _, targetChatID := range targets {
+		params := ForwardMessageParams{
+			ChatID:     targetChatID,
+			FromChatID: message.Chat.ID,
+			MessageID:  message.ReplyToMessage.MessageID,
+		}
 
-	_, err = h.client.ForwardMessage(ctx, params)
-	if err != nil {
-		return h.sendReply(ctx, message, "❌ 转发失败: "+err.Error())
+		if _, err := h.client.ForwardMessage(ctx, params); err != nil {
+			return h.sendReply(ctx, message, "❌ 转发失败: "+err.Error())
+		}
 	}
 
 	return h.sendReply(ctx, message, "✅ 消息已成功转发")
@@ -211,13 +781,13 @@ func (h *MessageHandler) handleForwardCommand(ctx context.Context, message *Mess
 
 // handleBanCommand 处理 /ban 命令
 func (h *MessageHandler) handleBanCommand(ctx context.Context, message *Message, args []string) error {
-	// 检查用户权限
-	if !h.isUserAdmin(ctx, message.Chat.ID, message.From.ID) {
-		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	// 检查用户和Bot的限制成员权限
+	if ok, err := h.requireRight(ctx, message, RightRestrictMembers); !ok {
+		return err
 	}
 
 	if len(args) == 0 {
-		return h.sendReply(ctx, message, "❌ 请指定要禁言的用户\n用法: /ban <@用户名> [原因]")
+		return h.sendReply(ctx, message, "❌ 请指定要禁言的用户\n用法: /ban <@用户名> [时长] [原因]")
 	}
 
 	// 解析用户ID (这里简化处理，实际应该支持@username)
@@ -227,11 +797,24 @@ func (h *MessageHandler) handleBanCommand(ctx context.Context, message *Message,
 		return h.sendReply(ctx, message, "❌ 无效的用户ID")
 	}
 
+	// 可选的时长参数
+	rest := args[1:]
+	var duration time.Duration
+	if len(rest) > 0 {
+		if d, ok := parseDuration(rest[0]); ok {
+			duration = d
+			rest = rest[1:]
+		}
+	}
+
 	// 执行禁言
 	params := BanChatMemberParams{
 		ChatID: message.Chat.ID,
 		UserID: userID,
 	}
+	if duration > 0 {
+		params.UntilDate = time.Now().Add(duration).Unix()
+	}
 
 	err = h.client.BanChatMember(ctx, params)
 	if err != nil {
@@ -239,62 +822,37 @@ func (h *MessageHandler) handleBanCommand(ctx context.Context, message *Message,
 	}
 
 	reason := "违反群规"
-	if len(args) > 1 {
-		reason = strings.Join(args[1:], " ")
+	if len(rest) > 0 {
+		reason = strings.Join(rest, " ")
 	}
 
-	return h.sendReply(ctx, message, fmt.Sprintf("✅ 用户已被禁言\n原因: %s", reason))
-}
-
-// handlePromoteCommand 处理 /promote 命令
-func (h *MessageHandler) handlePromoteCommand(ctx context.Context, message *Message, args []string) error {
-	// 检查用户权限
-	if !h.isUserAdmin(ctx, message.Chat.ID, message.From.ID) {
-		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
-	}
+	h.logModAction(ctx, ModAction{
+		Action:   ModActionBan,
+		Chat:     message.Chat,
+		Actor:    message.From,
+		TargetID: userID,
+		Reason:   reason,
+		Duration: duration,
+		Message:  message,
+	})
 
-	if len(args) == 0 {
-		return h.sendReply(ctx, message, "❌ 请指定要提升的用户\n用法: /promote <@用户名>")
-	}
-
-	// 解析用户ID
-	userIDStr := strings.TrimPrefix(args[0], "@")
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
-	if err != nil {
-		return h.sendReply(ctx, message, "❌ 无效的用户ID")
-	}
-
-	// 提升为管理员
-	params := PromoteChatMemberParams{
-		ChatID:            message.Chat.ID,
-		UserID:            userID,
-		CanDeleteMessages: true,
-		CanRestrictMembers: true,
-		CanInviteUsers:    true,
-		CanPinMessages:    true,
-	}
-
-	err = h.client.PromoteChatMember(ctx, params)
-	if err != nil {
-		return h.sendReply(ctx, message, "❌ 提升管理员失败: "+err.Error())
-	}
-
-	return h.sendReply(ctx, message, "✅ 用户已被提升为管理员")
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 用户已被禁言\n时长: %s\n原因: %s", formatDuration(duration), reason))
 }
 
 // handleAdminsCommand 处理 /admins 命令
 func (h *MessageHandler) handleAdminsCommand(ctx context.Context, message *Message) error {
-	admins, err := h.client.GetChatAdministrators(ctx, message.Chat.ID)
+	entry, err := h.chatAdmins(ctx, message.Chat.ID)
 	if err != nil {
 		return h.sendReply(ctx, message, "❌ 获取管理员列表失败")
 	}
+	admins := entry.admins
 
 	var adminList strings.Builder
 	adminList.WriteString("👮‍♂️ 群组管理员列表:\n\n")
 
 	for i, admin := range admins {
 		adminList.WriteString(fmt.Sprintf("%d. %s", i+1, getUserName(admin.User)))
-		if admin.Status == "creator" {
+		if admin.Status == MemberStatusCreator {
 			adminList.WriteString(" 👑 (群主)")
 		}
 		adminList.WriteString("\n")
@@ -308,15 +866,10 @@ func (h *MessageHandler) handleUnknownCommand(ctx context.Context, message *Mess
 	return h.sendReply(ctx, message, fmt.Sprintf("❓ 未知命令: %s\n使用 /help 查看可用命令", command))
 }
 
-// isUserAdmin 检查用户是否为管理员
+// isUserAdmin 检查用户是否为管理员 (使用管理员缓存)
 func (h *MessageHandler) isUserAdmin(ctx context.Context, chatID, userID int64) bool {
-	member, err := h.client.GetChatMember(ctx, chatID, userID)
-	if err != nil {
-		log.Printf("检查用户权限时出错: %v", err)
-		return false
-	}
-
-	return member.Status == "administrator" || member.Status == "creator"
+	_, ok := h.adminMember(ctx, chatID, userID)
+	return ok
 }
 
 // sendReply 发送回复消息
@@ -330,6 +883,23 @@ func (h *MessageHandler) sendReply(ctx context.Context, originalMessage *Message
 	return err
 }
 
+// answerCallback 回答回调查询，text为空时仅停止按钮加载状态
+func (h *MessageHandler) answerCallback(ctx context.Context, query *CallbackQuery, text string, showAlert bool) error {
+	return h.client.AnswerCallbackQuery(ctx, AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+		Text:            text,
+		ShowAlert:       showAlert,
+	})
+}
+
+// formatUser 获取用户显示名称及ID，用于日志和通知
+func formatUser(user *User) string {
+	if user == nil {
+		return "Unknown"
+	}
+	return fmt.Sprintf("%s (%d)", getUserName(user), user.ID)
+}
+
 // getUserName 获取用户显示名称
 func getUserName(user *User) string {
 	if user == nil {
@@ -346,4 +916,4 @@ func getUserName(user *User) string {
 	}
 
 	return name
-} 
\ No newline at end of file
+}
@@ -0,0 +1,381 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// inviteJoinDedupWindow 同一用户的入群在该时间内只统计一次
+// (new_chat_members 消息和 chat_member 更新会同时到达)
+const inviteJoinDedupWindow = time.Minute
+
+// InviteLinkStats 邀请链接及其统计
+type InviteLinkStats struct {
+	Link               string `json:"link"`
+	Name               string `json:"name,omitempty"`
+	CreatedBy          int64  `json:"created_by,omitempty"`
+	CreatedAt          int64  `json:"created_at"`
+	ExpireDate         int64  `json:"expire_date,omitempty"`
+	MemberLimit        int    `json:"member_limit,omitempty"`
+	CreatesJoinRequest bool   `json:"creates_join_request,omitempty"`
+	Revoked            bool   `json:"revoked,omitempty"`
+	Requests           int    `json:"requests"` // 通过该链接提交的加群申请数
+	Joins              int    `json:"joins"`    // 通过该链接入群的人数
+}
+
+// inviteData 邀请链接统计的持久化结构
+type inviteData struct {
+	Links map[int64]map[string]*InviteLinkStats `json:"links"`
+	// 已提交申请、尚未入群的用户使用的链接
+	Pending map[int64]map[int64]string `json:"pending,omitempty"`
+}
+
+// InviteManager 记录Bot创建的邀请链接并统计入群情况
+type InviteManager struct {
+	mu          sync.Mutex
+	storage     *Storage
+	data        inviteData
+	recentJoins map[string]time.Time // chatID:userID -> 最近一次统计入群的时间
+}
+
+// NewInviteManager 创建邀请链接管理器并从存储中加载
+func NewInviteManager(storage *Storage) (*InviteManager, error) {
+	m := &InviteManager{
+		storage: storage,
+		data: inviteData{
+			Links:   make(map[int64]map[string]*InviteLinkStats),
+			Pending: make(map[int64]map[int64]string),
+		},
+		recentJoins: make(map[string]time.Time),
+	}
+
+	if err := storage.Load("invites", &m.data); err != nil {
+		return nil, err
+	}
+	if m.data.Links == nil {
+		m.data.Links = make(map[int64]map[string]*InviteLinkStats)
+	}
+	if m.data.Pending == nil {
+		m.data.Pending = make(map[int64]map[int64]string)
+	}
+
+	return m, nil
+}
+
+// stats 获取链接的统计，不存在时创建，调用方需持有锁
+func (m *InviteManager) stats(chatID int64, link string) *InviteLinkStats {
+	links, ok := m.data.Links[chatID]
+	if !ok {
+		links = make(map[string]*InviteLinkStats)
+		m.data.Links[chatID] = links
+	}
+
+	s, ok := links[link]
+	if !ok {
+		s = &InviteLinkStats{Link: link, CreatedAt: time.Now().Unix()}
+		links[link] = s
+	}
+	return s
+}
+
+// Track 记录新建或更新的邀请链接
+func (m *InviteManager) Track(chatID int64, link *ChatInviteLink, createdBy int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.stats(chatID, link.InviteLink)
+	s.Name = link.Name
+	s.ExpireDate = link.ExpireDate
+	s.MemberLimit = link.MemberLimit
+	s.CreatesJoinRequest = link.CreatesJoinRequest
+	s.Revoked = link.IsRevoked
+	if createdBy != 0 {
+		s.CreatedBy = createdBy
+	}
+
+	return m.storage.Save("invites", m.data)
+}
+
+// RecordRequest 统计一次通过链接提交的加群申请
+func (m *InviteManager) RecordRequest(chatID, userID int64, link string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stats(chatID, link).Requests++
+
+	pending, ok := m.data.Pending[chatID]
+	if !ok {
+		pending = make(map[int64]string)
+		m.data.Pending[chatID] = pending
+	}
+	pending[userID] = link
+
+	return m.storage.Save("invites", m.data)
+}
+
+// RecordJoin 统计一次入群，link 为空时使用该用户之前加群申请所用的链接
+// 返回是否计入了某个链接
+func (m *InviteManager) RecordJoin(chatID, userID int64, link string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for key, at := range m.recentJoins {
+		if now.Sub(at) > inviteJoinDedupWindow {
+			delete(m.recentJoins, key)
+		}
+	}
+
+	key := fmt.Sprintf("%d:%d", chatID, userID)
+	if _, ok := m.recentJoins[key]; ok {
+		return false, nil
+	}
+
+	if pending, ok := m.data.Pending[chatID]; ok {
+		if link == "" {
+			link = pending[userID]
+		}
+		delete(pending, userID)
+	}
+	if link == "" {
+		return false, nil
+	}
+
+	m.recentJoins[key] = now
+	m.stats(chatID, link).Joins++
+
+	return true, m.storage.Save("invites", m.data)
+}
+
+// List 列出聊天中记录的邀请链接 (按创建时间排序)
+func (m *InviteManager) List(chatID int64) []InviteLinkStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var list []InviteLinkStats
+	for _, s := range m.data.Links[chatID] {
+		list = append(list, *s)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt < list[j].CreatedAt
+	})
+	return list
+}
+
+// trackJoinRequest 统计加群申请使用的邀请链接
+func (h *MessageHandler) trackJoinRequest(request *ChatJoinRequest) {
+	if request.InviteLink == nil || request.From == nil {
+		return
+	}
+
+	if err := h.invites.RecordRequest(request.Chat.ID, request.From.ID, request.InviteLink.InviteLink); err != nil {
+		log.Printf("保存邀请链接统计失败: %v", err)
+	}
+}
+
+// trackInviteJoin 统计成员入群使用的邀请链接
+func (h *MessageHandler) trackInviteJoin(chatID, userID int64, link string) {
+	if _, err := h.invites.RecordJoin(chatID, userID, link); err != nil {
+		log.Printf("保存邀请链接统计失败: %v", err)
+	}
+}
+
+// trackChatMemberJoin 根据 chat_member 更新统计入群使用的邀请链接
+func (h *MessageHandler) trackChatMemberJoin(update *ChatMemberUpdated) {
+	joined := !isMemberPresent(update.OldChatMember) && isMemberPresent(update.NewChatMember)
+	if !joined || update.NewChatMember.User == nil {
+		return
+	}
+
+	link := ""
+	if update.InviteLink != nil {
+		link = update.InviteLink.InviteLink
+	}
+	h.trackInviteJoin(update.Chat.ID, update.NewChatMember.User.ID, link)
+}
+
+// isMemberPresent 成员是否在聊天中
+func isMemberPresent(member *ChatMember) bool {
+	if member == nil {
+		return false
+	}
+	if member.Status == MemberStatusRestricted {
+		return member.IsMember
+	}
+	return member.Status != MemberStatusLeft && member.Status != MemberStatusKicked
+}
+
+// handleInviteLinkCommand 处理 /invitelink 命令，生成新的主邀请链接
+func (h *MessageHandler) handleInviteLinkCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightInviteUsers); !ok {
+		return err
+	}
+
+	link, err := h.client.ExportChatInviteLink(ctx, message.Chat.ID)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 生成邀请链接失败: "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, "🔗 新的主邀请链接 (旧链接已失效):\n"+link)
+}
+
+// handleNewLinkCommand 处理 /newlink 命令，创建附加邀请链接
+// 用法: /newlink <名称> [有效期] [人数上限] [request]
+func (h *MessageHandler) handleNewLinkCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightInviteUsers); !ok {
+		return err
+	}
+
+	usage := "❌ 用法: /newlink <名称> [有效期] [人数上限] [request]\n例如: /newlink 活动推广 7d 100"
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, usage)
+	}
+
+	params := ChatInviteLinkParams{
+		ChatID: message.Chat.ID,
+		Name:   args[0],
+	}
+	if len([]rune(params.Name)) > 32 {
+		return h.sendReply(ctx, message, "❌ 链接名称最多 32 个字符")
+	}
+
+	for _, arg := range args[1:] {
+		if strings.ToLower(arg) == "request" {
+			params.CreatesJoinRequest = true
+			continue
+		}
+		if d, ok := parseDuration(arg); ok {
+			params.ExpireDate = time.Now().Add(d).Unix()
+			continue
+		}
+		if n, err := strconv.Atoi(arg); err == nil && n >= 1 && n <= 99999 {
+			params.MemberLimit = n
+			continue
+		}
+		return h.sendReply(ctx, message, usage)
+	}
+
+	// 需要审批的链接不能设置人数上限
+	if params.CreatesJoinRequest && params.MemberLimit > 0 {
+		return h.sendReply(ctx, message, "❌ 需要审批的链接不能设置人数上限")
+	}
+
+	link, err := h.client.CreateChatInviteLink(ctx, params)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 创建邀请链接失败: "+err.Error())
+	}
+
+	if err := h.invites.Track(message.Chat.ID, link, message.From.ID); err != nil {
+		log.Printf("保存邀请链接失败: %v", err)
+	}
+
+	return h.sendReply(ctx, message, "✅ 已创建邀请链接\n"+formatInviteLink(InviteLinkStats{
+		Link:               link.InviteLink,
+		Name:               link.Name,
+		ExpireDate:         link.ExpireDate,
+		MemberLimit:        link.MemberLimit,
+		CreatesJoinRequest: link.CreatesJoinRequest,
+	}))
+}
+
+// handleLinksCommand 处理 /links 命令，列出Bot记录的邀请链接及统计
+func (h *MessageHandler) handleLinksCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	links := h.invites.List(message.Chat.ID)
+	if len(links) == 0 {
+		return h.sendReply(ctx, message, "📭 还没有记录的邀请链接，使用 /newlink 创建")
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🔗 邀请链接 (%d):\n", len(links)))
+	for _, link := range links {
+		b.WriteString("\n")
+		b.WriteString(formatInviteLink(link))
+	}
+
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID:                message.Chat.ID,
+		Text:                  b.String(),
+		ReplyToMessageID:      message.MessageID,
+		DisableWebPagePreview: true,
+	})
+	return err
+}
+
+// handleRevokeCommand 处理 /revoke <链接> 命令
+func (h *MessageHandler) handleRevokeCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightInviteUsers); !ok {
+		return err
+	}
+
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, "❌ 用法: /revoke <邀请链接>")
+	}
+
+	link, err := h.client.RevokeChatInviteLink(ctx, message.Chat.ID, args[0])
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 撤销邀请链接失败: "+err.Error())
+	}
+
+	if err := h.invites.Track(message.Chat.ID, link, 0); err != nil {
+		log.Printf("保存邀请链接失败: %v", err)
+	}
+
+	return h.sendReply(ctx, message, "✅ 已撤销邀请链接: "+link.InviteLink)
+}
+
+// formatInviteLink 格式化邀请链接及统计
+func formatInviteLink(link InviteLinkStats) string {
+	var b strings.Builder
+
+	name := link.Name
+	if name == "" {
+		name = "未命名"
+	}
+	b.WriteString(fmt.Sprintf("• %s", name))
+	if link.Revoked {
+		b.WriteString(" (已撤销)")
+	} else if link.ExpireDate > 0 && time.Now().Unix() > link.ExpireDate {
+		b.WriteString(" (已过期)")
+	}
+	b.WriteString("\n  " + link.Link + "\n")
+
+	if link.ExpireDate > 0 {
+		b.WriteString(fmt.Sprintf("  有效期至: %s\n", time.Unix(link.ExpireDate, 0).Format("2006-01-02 15:04")))
+	}
+	if link.MemberLimit > 0 {
+		b.WriteString(fmt.Sprintf("  人数上限: %d\n", link.MemberLimit))
+	}
+	if link.CreatesJoinRequest {
+		b.WriteString(fmt.Sprintf("  需要审批，申请数: %d\n", link.Requests))
+	}
+	b.WriteString(fmt.Sprintf("  入群人数: %d\n", link.Joins))
+
+	return b.String()
+}
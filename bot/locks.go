@@ -0,0 +1,309 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// 可锁定的内容类型
+const (
+	LockURL      = "url"
+	LockMention  = "mention"
+	LockForward  = "forward"
+	LockPhoto    = "photo"
+	LockVideo    = "video"
+	LockDocument = "document"
+	LockVoice    = "voice"
+	LockSticker  = "sticker"
+	LockContact  = "contact"
+	LockLocation = "location"
+	LockBots     = "bots" // 禁止非管理员拉入Bot
+)
+
+// lockTypes 可锁定的类型 (按显示顺序)
+var lockTypes = []string{
+	LockURL, LockMention, LockForward, LockPhoto, LockVideo, LockDocument,
+	LockVoice, LockSticker, LockContact, LockLocation, LockBots,
+}
+
+// lockLabels 锁定类型显示名称
+var lockLabels = map[string]string{
+	LockURL:      "链接",
+	LockMention:  "提及",
+	LockForward:  "转发",
+	LockPhoto:    "图片",
+	LockVideo:    "视频",
+	LockDocument: "文件",
+	LockVoice:    "语音",
+	LockSticker:  "贴纸",
+	LockContact:  "联系人",
+	LockLocation: "位置",
+	LockBots:     "拉入Bot",
+}
+
+// isLockType 检查是否为支持的锁定类型
+func isLockType(lock string) bool {
+	_, ok := lockLabels[lock]
+	return ok
+}
+
+// violatedLock 返回消息违反的第一个锁定类型，没有违反时返回空字符串
+func violatedLock(s *ChatSettings, message *Message) string {
+	locked := func(lock string) bool { return s.Locks[lock] }
+
+	switch {
+	// 关联频道自动转发到讨论组的帖子不算成员转发
+	case locked(LockForward) && message.IsForwarded() && !message.IsAutomaticForward:
+		return LockForward
+	case locked(LockPhoto) && len(message.Photo) > 0:
+		return LockPhoto
+	case locked(LockVideo) && message.Video != nil:
+		return LockVideo
+	case locked(LockDocument) && message.Document != nil:
+		return LockDocument
+	case locked(LockVoice) && message.Voice != nil:
+		return LockVoice
+	case locked(LockSticker) && message.Sticker != nil:
+		return LockSticker
+	case locked(LockContact) && message.Contact != nil:
+		return LockContact
+	case locked(LockLocation) && message.Location != nil:
+		return LockLocation
+	}
+
+	if !locked(LockURL) && !locked(LockMention) {
+		return ""
+	}
+
+	text := messageText(message)
+	for _, entity := range messageEntities(message) {
+		switch entity.Type {
+		case "url":
+			if locked(LockURL) && !isDomainAllowed(s.AllowedDomains, entityText(text, entity)) {
+				return LockURL
+			}
+		case "text_link":
+			if locked(LockURL) && !isDomainAllowed(s.AllowedDomains, entity.URL) {
+				return LockURL
+			}
+		case "mention", "text_mention":
+			if locked(LockMention) {
+				return LockMention
+			}
+		}
+	}
+	return ""
+}
+
+// linkHost 取出链接的域名 (小写)
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// isDomainAllowed 检查链接是否属于白名单域名 (包括其子域名)
+func isDomainAllowed(allowed []string, link string) bool {
+	host := linkHost(link)
+	if host == "" {
+		return false
+	}
+
+	for _, domain := range allowed {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLocks 检查消息是否违反锁定设置，违反时删除消息并返回 true
+func (h *MessageHandler) checkLocks(ctx context.Context, message *Message) bool {
+	if message.Chat.Type == "private" {
+		return false
+	}
+
+	settings := h.settings.Get(message.Chat.ID)
+	if len(settings.Locks) == 0 {
+		return false
+	}
+
+	lock := violatedLock(settings, message)
+	if lock == "" {
+		return false
+	}
+
+	// 违反锁定后才检查管理员身份，管理员不受限制
+	if h.isSenderAdmin(ctx, message) {
+		return false
+	}
+
+	if err := h.client.DeleteMessage(ctx, message.Chat.ID, message.MessageID); err != nil {
+		log.Printf("删除违反锁定(%s)的消息失败: %v", lock, err)
+	}
+	return true
+}
+
+// checkBotsLock 非管理员拉入Bot时将其移出群组
+func (h *MessageHandler) checkBotsLock(ctx context.Context, message *Message) {
+	if !h.settings.Get(message.Chat.ID).Locks[LockBots] {
+		return
+	}
+
+	var bots []User
+	for _, member := range message.NewChatMembers {
+		if member.IsBot && (h.botUser == nil || member.ID != h.botUser.ID) {
+			bots = append(bots, member)
+		}
+	}
+	if len(bots) == 0 || h.isSenderAdmin(ctx, message) {
+		return
+	}
+
+	chatID := message.Chat.ID
+	for _, bot := range bots {
+		if err := h.client.BanChatMember(ctx, BanChatMemberParams{ChatID: chatID, UserID: bot.ID}); err != nil {
+			log.Printf("移出Bot %s 失败: %v", getUserName(&bot), err)
+			continue
+		}
+		if err := h.client.UnbanChatMember(ctx, UnbanChatMemberParams{ChatID: chatID, UserID: bot.ID, OnlyIfBanned: true}); err != nil {
+			log.Printf("解除Bot %s 的封禁失败: %v", getUserName(&bot), err)
+		}
+
+		text := fmt.Sprintf("🤖 本群已锁定拉入Bot，%s 已被移出", getUserName(&bot))
+		if err := h.sendReply(ctx, message, text); err != nil {
+			log.Printf("发送消息失败: %v", err)
+		}
+	}
+}
+
+// handleLockCommand 处理 /lock 和 /unlock 命令
+func (h *MessageHandler) handleLockCommand(ctx context.Context, message *Message, args []string, lock bool) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightDeleteMessages); !ok {
+		return err
+	}
+
+	command := "/unlock"
+	if lock {
+		command = "/lock"
+	}
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, fmt.Sprintf("❌ 用法: %s <类型...>\n可用类型: %s", command, strings.Join(lockTypes, ", ")))
+	}
+
+	var types []string
+	for _, arg := range args {
+		t := strings.ToLower(arg)
+		if !isLockType(t) {
+			return h.sendReply(ctx, message, fmt.Sprintf("❌ 未知的类型: %s\n可用类型: %s", arg, strings.Join(lockTypes, ", ")))
+		}
+		types = append(types, t)
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		for _, t := range types {
+			if lock {
+				s.Locks[t] = true
+			} else {
+				delete(s.Locks, t)
+			}
+		}
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	labels := make([]string, len(types))
+	for i, t := range types {
+		labels[i] = lockLabels[t]
+	}
+	if lock {
+		return h.sendReply(ctx, message, "🔒 已锁定: "+strings.Join(labels, "、"))
+	}
+	return h.sendReply(ctx, message, "🔓 已解锁: "+strings.Join(labels, "、"))
+}
+
+// handleLocksCommand 处理 /locks 命令，查看锁定状态或管理链接白名单
+// 用法: /locks | /locks allow <域名> | /locks disallow <域名>
+func (h *MessageHandler) handleLocksCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if len(args) == 0 {
+		if !h.isSenderAdmin(ctx, message) {
+			return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+		}
+		return h.sendReply(ctx, message, formatLocks(h.settings.Get(message.Chat.ID)))
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightDeleteMessages); !ok {
+		return err
+	}
+
+	action := strings.ToLower(args[0])
+	if (action != "allow" && action != "disallow") || len(args) < 2 {
+		return h.sendReply(ctx, message, "❌ 用法: /locks allow <域名> 或 /locks disallow <域名>")
+	}
+
+	domain := linkHost(args[1])
+	if domain == "" {
+		return h.sendReply(ctx, message, "❌ 无效的域名")
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		var domains []string
+		for _, d := range s.AllowedDomains {
+			if d != domain {
+				domains = append(domains, d)
+			}
+		}
+		if action == "allow" {
+			domains = append(domains, domain)
+		}
+		s.AllowedDomains = domains
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	if action == "allow" {
+		return h.sendReply(ctx, message, "✅ 已将域名加入白名单: "+domain)
+	}
+	return h.sendReply(ctx, message, "✅ 已将域名移出白名单: "+domain)
+}
+
+// formatLocks 格式化锁定状态
+func formatLocks(s *ChatSettings) string {
+	var b strings.Builder
+	b.WriteString("🔐 锁定状态:\n\n")
+	for _, t := range lockTypes {
+		icon := "🔓"
+		if s.Locks[t] {
+			icon = "🔒"
+		}
+		b.WriteString(fmt.Sprintf("%s %s (%s)\n", icon, lockLabels[t], t))
+	}
+
+	b.WriteString("\n链接白名单: ")
+	if len(s.AllowedDomains) == 0 {
+		b.WriteString("无")
+	} else {
+		b.WriteString(strings.Join(s.AllowedDomains, ", "))
+	}
+	return b.String()
+}
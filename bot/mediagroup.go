@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// mediaGroupWait 收到相册最后一部分后等待的时间，超时即认为相册已完整
+const mediaGroupWait = 1500 * time.Millisecond
+
+// pendingMediaGroup 正在收集的相册
+type pendingMediaGroup struct {
+	messages []*Message
+	timer    *time.Timer
+}
+
+// mediaGroupCollector 按 media_group_id 聚合相册的各个部分
+// 相册的每一项都是一条独立的消息，短时间内没有新部分到达时统一交给回调处理
+type mediaGroupCollector struct {
+	mu      sync.Mutex
+	wait    time.Duration
+	pending map[string]*pendingMediaGroup
+	flush   func(ctx context.Context, messages []*Message)
+}
+
+// newMediaGroupCollector 创建相册聚合器
+func newMediaGroupCollector(wait time.Duration, flush func(ctx context.Context, messages []*Message)) *mediaGroupCollector {
+	return &mediaGroupCollector{
+		wait:    wait,
+		pending: make(map[string]*pendingMediaGroup),
+		flush:   flush,
+	}
+}
+
+// Add 加入一条相册消息，每收到一部分都会重新计时
+func (c *mediaGroupCollector) Add(ctx context.Context, message *Message) {
+	key := message.MediaGroupID
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	group, ok := c.pending[key]
+	if !ok {
+		group = &pendingMediaGroup{}
+		c.pending[key] = group
+		group.timer = time.AfterFunc(c.wait, func() { c.complete(ctx, key) })
+	} else {
+		group.timer.Reset(c.wait)
+	}
+
+	group.messages = append(group.messages, message)
+}
+
+// complete 取出已完整的相册并调用回调
+func (c *mediaGroupCollector) complete(ctx context.Context, key string) {
+	c.mu.Lock()
+	group, ok := c.pending[key]
+	delete(c.pending, key)
+	c.mu.Unlock()
+
+	if !ok || ctx.Err() != nil {
+		return
+	}
+
+	// 按消息ID排序，保持相册原有顺序
+	messages := group.messages
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].MessageID < messages[j].MessageID
+	})
+
+	c.flush(ctx, messages)
+}
+
+// applyForwardRulesToAlbum 对完整的相册执行转发规则
+// 相册中任意一项命中即视为整个相册命中 (说明文字通常只在第一项上)
+func (h *MessageHandler) applyForwardRulesToAlbum(ctx context.Context, messages []*Message) {
+	matched := make(map[int]ForwardRule)
+	var order []int
+	for _, message := range messages {
+		for _, rule := range h.rules.Match(message) {
+			if _, ok := matched[rule.ID]; !ok {
+				matched[rule.ID] = rule
+				order = append(order, rule.ID)
+			}
+		}
+	}
+
+	for _, id := range order {
+		rule := matched[id]
+		for _, target := range rule.Targets {
+			if err := h.forwardAlbumByRule(ctx, rule, messages, target); err != nil {
+				log.Printf("规则 #%d 转发相册到 %d 失败: %v", rule.ID, target, err)
+			}
+		}
+	}
+}
+
+// forwardAlbumByRule 按规则的转发方式把整个相册发送到目标聊天
+func (h *MessageHandler) forwardAlbumByRule(ctx context.Context, rule ForwardRule, messages []*Message, target int64) error {
+	fromChatID := messages[0].Chat.ID
+	ids := make([]int, len(messages))
+	for i, message := range messages {
+		ids[i] = message.MessageID
+	}
+
+	if rule.Mode != RuleModeCopy {
+		_, err := h.client.ForwardMessages(ctx, ForwardMessagesParams{
+			ChatID:     target,
+			FromChatID: fromChatID,
+			MessageIDs: ids,
+		})
+		return err
+	}
+
+	if rule.Caption == "" {
+		_, err := h.client.CopyMessages(ctx, CopyMessagesParams{
+			ChatID:     target,
+			FromChatID: fromChatID,
+			MessageIDs: ids,
+		})
+		return err
+	}
+
+	// 使用说明模板时按 file_id 重新组装相册，模板渲染在带说明的那一项上
+	captioned := messages[0]
+	for _, message := range messages {
+		if message.Caption != "" {
+			captioned = message
+			break
+		}
+	}
+
+	var media []InputMedia
+	for _, message := range messages {
+		item, ok := inputMediaFromMessage(message)
+		if !ok {
+			continue
+		}
+		item.Caption = ""
+		if message == captioned {
+			item.Caption = renderCaptionTemplate(rule.Caption, captioned)
+		}
+		media = append(media, item)
+	}
+
+	_, err := h.client.SendMediaGroup(ctx, SendMediaGroupParams{
+		ChatID: target,
+		Media:  media,
+	})
+	return err
+}
+
+// inputMediaFromMessage 把相册中的一项转换为 sendMediaGroup 使用的 InputMedia
+func inputMediaFromMessage(message *Message) (InputMedia, bool) {
+	item := InputMedia{Caption: message.Caption}
+
+	switch {
+	case len(message.Photo) > 0:
+		item.Type = "photo"
+		item.Media = message.Photo[len(message.Photo)-1].FileID
+	case message.Video != nil:
+		item.Type = "video"
+		item.Media = message.Video.FileID
+	case message.Document != nil:
+		item.Type = "document"
+		item.Media = message.Document.FileID
+	case message.Audio != nil:
+		item.Type = "audio"
+		item.Media = message.Audio.FileID
+	default:
+		return item, false
+	}
+
+	return item, true
+}
@@ -0,0 +1,495 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mirrorMaxRecords 最多保留的源消息→镜像消息映射条数，超出后丢弃最旧的记录
+const mirrorMaxRecords = 5000
+
+// mirrorFlushInterval 消息映射写入磁盘的间隔
+// 映射随每条频道消息变化，不逐条保存，避免频繁重写整个文件
+const mirrorFlushInterval = time.Minute
+
+// MirrorConfig 一个源频道的镜像配置
+type MirrorConfig struct {
+	SourceChatID int64   `json:"source_chat_id"`
+	Targets      []int64 `json:"targets"`
+	SyncDelete   bool    `json:"sync_delete"` // 在源频道用 /delete 删除时是否同步删除镜像
+	CreatedBy    int64   `json:"created_by"`
+}
+
+// mirroredMessage 镜像频道中的一条消息
+type mirroredMessage struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int   `json:"message_id"`
+}
+
+// mirrorRecord 源消息与其所有镜像消息的对应关系
+type mirrorRecord struct {
+	SourceChatID    int64             `json:"source_chat_id"`
+	SourceMessageID int               `json:"source_message_id"`
+	Copies          []mirroredMessage `json:"copies"`
+	CreatedAt       int64             `json:"created_at"`
+}
+
+// mirrorData 镜像的持久化结构
+type mirrorData struct {
+	Mirrors []*MirrorConfig `json:"mirrors"`
+	Records []mirrorRecord  `json:"records"`
+}
+
+// MirrorManager 管理频道镜像配置和消息映射
+type MirrorManager struct {
+	mu      sync.RWMutex
+	storage *Storage
+	data    mirrorData
+	dirty   bool // 消息映射有尚未保存的修改
+}
+
+// NewMirrorManager 创建镜像管理器并从存储中加载已有配置
+func NewMirrorManager(storage *Storage) (*MirrorManager, error) {
+	m := &MirrorManager{storage: storage}
+
+	if err := storage.Load("mirrors", &m.data); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Get 获取源频道的镜像配置
+func (m *MirrorManager) Get(sourceChatID int64) (MirrorConfig, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, mirror := range m.data.Mirrors {
+		if mirror.SourceChatID == sourceChatID {
+			c := *mirror
+			c.Targets = append([]int64(nil), mirror.Targets...)
+			return c, true
+		}
+	}
+	return MirrorConfig{}, false
+}
+
+// List 列出所有镜像配置
+func (m *MirrorManager) List() []MirrorConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mirrors := make([]MirrorConfig, len(m.data.Mirrors))
+	for i, mirror := range m.data.Mirrors {
+		mirrors[i] = *mirror
+		mirrors[i].Targets = append([]int64(nil), mirror.Targets...)
+	}
+	return mirrors
+}
+
+// AddTargets 为源频道添加镜像目标
+func (m *MirrorManager) AddTargets(sourceChatID int64, targets []int64, createdBy int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var mirror *MirrorConfig
+	for _, c := range m.data.Mirrors {
+		if c.SourceChatID == sourceChatID {
+			mirror = c
+			break
+		}
+	}
+	if mirror == nil {
+		mirror = &MirrorConfig{SourceChatID: sourceChatID, SyncDelete: true, CreatedBy: createdBy}
+		m.data.Mirrors = append(m.data.Mirrors, mirror)
+	}
+
+	// 总是构建新的切片，不修改 Get/List 返回的副本可能共享的底层数组
+	updated := append([]int64(nil), mirror.Targets...)
+	for _, target := range targets {
+		if !containsInt64(updated, target) {
+			updated = append(updated, target)
+		}
+	}
+	mirror.Targets = updated
+
+	return m.save()
+}
+
+// Remove 移除源频道的某个镜像目标，target 为0时移除整个镜像
+func (m *MirrorManager) Remove(sourceChatID, target int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, mirror := range m.data.Mirrors {
+		if mirror.SourceChatID != sourceChatID {
+			continue
+		}
+
+		if target != 0 {
+			if !containsInt64(mirror.Targets, target) {
+				return false, nil
+			}
+			var targets []int64
+			for _, t := range mirror.Targets {
+				if t != target {
+					targets = append(targets, t)
+				}
+			}
+			mirror.Targets = targets
+		}
+
+		if target == 0 || len(mirror.Targets) == 0 {
+			m.data.Mirrors = append(m.data.Mirrors[:i], m.data.Mirrors[i+1:]...)
+		}
+		return true, m.save()
+	}
+
+	return false, nil
+}
+
+// SetSyncDelete 设置是否同步删除
+func (m *MirrorManager) SetSyncDelete(sourceChatID int64, on bool) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, mirror := range m.data.Mirrors {
+		if mirror.SourceChatID == sourceChatID {
+			mirror.SyncDelete = on
+			return true, m.save()
+		}
+	}
+	return false, nil
+}
+
+// save 保存镜像配置和消息映射，调用方需持有写锁
+func (m *MirrorManager) save() error {
+	if err := m.storage.Save("mirrors", m.data); err != nil {
+		return err
+	}
+	m.dirty = false
+	return nil
+}
+
+// Flush 保存尚未写入磁盘的消息映射
+func (m *MirrorManager) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.dirty {
+		return nil
+	}
+	return m.save()
+}
+
+// Record 记录源消息与镜像消息的对应关系，由 Flush 定期保存
+func (m *MirrorManager) Record(sourceChatID int64, sourceMessageID int, copies []mirroredMessage) {
+	if len(copies) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.Records = append(m.data.Records, mirrorRecord{
+		SourceChatID:    sourceChatID,
+		SourceMessageID: sourceMessageID,
+		Copies:          copies,
+		CreatedAt:       time.Now().Unix(),
+	})
+	if overflow := len(m.data.Records) - mirrorMaxRecords; overflow > 0 {
+		m.data.Records = append([]mirrorRecord(nil), m.data.Records[overflow:]...)
+	}
+	m.dirty = true
+}
+
+// Copies 查找源消息对应的所有镜像消息
+func (m *MirrorManager) Copies(sourceChatID int64, sourceMessageID int) []mirroredMessage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// 从后往前查找，最近的消息最可能被编辑
+	for i := len(m.data.Records) - 1; i >= 0; i-- {
+		r := m.data.Records[i]
+		if r.SourceChatID == sourceChatID && r.SourceMessageID == sourceMessageID {
+			return append([]mirroredMessage(nil), r.Copies...)
+		}
+	}
+	return nil
+}
+
+// Forget 删除源消息的映射记录，由 Flush 定期保存
+func (m *MirrorManager) Forget(sourceChatID int64, sourceMessageID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, r := range m.data.Records {
+		if r.SourceChatID == sourceChatID && r.SourceMessageID == sourceMessageID {
+			m.data.Records = append(m.data.Records[:i], m.data.Records[i+1:]...)
+			m.dirty = true
+			return
+		}
+	}
+}
+
+// flushMirrorRecords 定期保存镜像消息映射
+func (h *MessageHandler) flushMirrorRecords(ctx context.Context) {
+	if err := h.mirrors.Flush(); err != nil {
+		log.Printf("保存镜像映射失败: %v", err)
+	}
+}
+
+// mirrorChannelPost 把源频道的新消息复制到所有镜像频道
+func (h *MessageHandler) mirrorChannelPost(ctx context.Context, post *Message) {
+	mirror, ok := h.mirrors.Get(post.Chat.ID)
+	if !ok || len(mirror.Targets) == 0 {
+		return
+	}
+
+	// 相册需要等所有部分到齐后整体复制
+	if post.MediaGroupID != "" {
+		h.mirrorAlbums.Add(ctx, post)
+		return
+	}
+
+	var copies []mirroredMessage
+	for _, target := range mirror.Targets {
+		copied, err := h.client.CopyMessage(ctx, CopyMessageParams{
+			ChatID:     target,
+			FromChatID: post.Chat.ID,
+			MessageID:  post.MessageID,
+		})
+		if err != nil {
+			log.Printf("镜像消息 %d 到 %d 失败: %v", post.MessageID, target, err)
+			continue
+		}
+		copies = append(copies, mirroredMessage{ChatID: target, MessageID: copied.MessageID})
+	}
+
+	h.mirrors.Record(post.Chat.ID, post.MessageID, copies)
+}
+
+// mirrorAlbum 把源频道的相册整体复制到所有镜像频道
+func (h *MessageHandler) mirrorAlbum(ctx context.Context, posts []*Message) {
+	source := posts[0].Chat.ID
+	mirror, ok := h.mirrors.Get(source)
+	if !ok {
+		return
+	}
+
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.MessageID
+	}
+
+	copies := make([][]mirroredMessage, len(posts))
+	for _, target := range mirror.Targets {
+		copied, err := h.client.CopyMessages(ctx, CopyMessagesParams{
+			ChatID:     target,
+			FromChatID: source,
+			MessageIDs: ids,
+		})
+		if err != nil {
+			log.Printf("镜像相册到 %d 失败: %v", target, err)
+			continue
+		}
+
+		// copyMessages 按请求顺序返回新消息ID
+		for i := range copied {
+			if i < len(posts) {
+				copies[i] = append(copies[i], mirroredMessage{ChatID: target, MessageID: copied[i].MessageID})
+			}
+		}
+	}
+
+	for i, post := range posts {
+		h.mirrors.Record(source, post.MessageID, copies[i])
+	}
+}
+
+// propagateMirrorEdit 把源频道中被编辑的消息同步到所有镜像
+func (h *MessageHandler) propagateMirrorEdit(ctx context.Context, post *Message) {
+	for _, c := range h.mirrors.Copies(post.Chat.ID, post.MessageID) {
+		var err error
+		if post.Text != "" {
+			err = h.client.EditMessageText(ctx, EditMessageTextParams{
+				ChatID:    c.ChatID,
+				MessageID: c.MessageID,
+				Text:      post.Text,
+				Entities:  post.Entities,
+			})
+		} else {
+			err = h.client.EditMessageCaption(ctx, EditMessageCaptionParams{
+				ChatID:          c.ChatID,
+				MessageID:       c.MessageID,
+				Caption:         post.Caption,
+				CaptionEntities: post.CaptionEntities,
+			})
+		}
+		if err != nil && !strings.Contains(err.Error(), "message is not modified") {
+			log.Printf("同步编辑到 %d/%d 失败: %v", c.ChatID, c.MessageID, err)
+		}
+	}
+}
+
+// handleChannelDelete 处理源频道中回复某条消息发送的 /delete
+// Bot API 不会推送删除事件，因此通过该命令删除源消息并同步删除镜像
+func (h *MessageHandler) handleChannelDelete(ctx context.Context, post *Message) error {
+	// 无论结果如何都删除命令本身
+	defer func() {
+		if err := h.client.DeleteMessage(ctx, post.Chat.ID, post.MessageID); err != nil {
+			log.Printf("删除频道命令消息失败: %v", err)
+		}
+	}()
+
+	target := post.ReplyToMessage
+	if target == nil {
+		return nil
+	}
+
+	if err := h.client.DeleteMessage(ctx, post.Chat.ID, target.MessageID); err != nil {
+		log.Printf("删除频道消息失败: %v", err)
+	}
+
+	mirror, ok := h.mirrors.Get(post.Chat.ID)
+	if !ok || !mirror.SyncDelete {
+		return nil
+	}
+
+	for _, c := range h.mirrors.Copies(post.Chat.ID, target.MessageID) {
+		if err := h.client.DeleteMessage(ctx, c.ChatID, c.MessageID); err != nil {
+			log.Printf("同步删除 %d/%d 失败: %v", c.ChatID, c.MessageID, err)
+		}
+	}
+
+	h.mirrors.Forget(post.Chat.ID, target.MessageID)
+	return nil
+}
+
+// handleMirrorCommand 处理 /mirror 命令
+// 用法: /mirror add <源频道ID> <目标ID...> | remove <源频道ID> [目标ID] | delete <源频道ID> on|off | list
+func (h *MessageHandler) handleMirrorCommand(ctx context.Context, message *Message, args []string) error {
+	usage := "用法:\n/mirror add <源频道ID> <目标频道ID...>\n/mirror remove <源频道ID> [目标频道ID]\n/mirror delete <源频道ID> on|off\n/mirror list"
+
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, "❌ 缺少参数\n"+usage)
+	}
+
+	if strings.ToLower(args[0]) == "list" {
+		return h.sendReply(ctx, message, h.formatMirrorList(ctx, message.From.ID))
+	}
+
+	if len(args) < 2 {
+		return h.sendReply(ctx, message, "❌ 缺少参数\n"+usage)
+	}
+
+	ids := make([]int64, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		if arg == "on" || arg == "off" {
+			continue
+		}
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return h.sendReply(ctx, message, "❌ 无效的频道ID: "+arg)
+		}
+		ids = append(ids, id)
+	}
+
+	// 必须同时是源频道和所有目标频道的管理员
+	for _, id := range ids {
+		if !h.isUserAdmin(ctx, id, message.From.ID) {
+			return h.sendReply(ctx, message, fmt.Sprintf("❌ 您不是 %d 的管理员", id))
+		}
+	}
+
+	source := ids[0]
+	switch strings.ToLower(args[0]) {
+	case "add":
+		if len(ids) < 2 {
+			return h.sendReply(ctx, message, "❌ 请指定至少一个目标频道\n"+usage)
+		}
+		if err := h.mirrors.AddTargets(source, ids[1:], message.From.ID); err != nil {
+			log.Printf("保存镜像配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+		return h.sendReply(ctx, message, fmt.Sprintf("✅ 已将 %d 镜像到 %d 个目标", source, len(ids)-1))
+
+	case "remove":
+		var target int64
+		if len(ids) > 1 {
+			target = ids[1]
+		}
+		removed, err := h.mirrors.Remove(source, target)
+		if err != nil {
+			log.Printf("保存镜像配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+		if !removed {
+			return h.sendReply(ctx, message, "❌ 镜像配置不存在")
+		}
+		return h.sendReply(ctx, message, "✅ 已移除镜像")
+
+	case "delete":
+		var on bool
+		switch strings.ToLower(args[len(args)-1]) {
+		case "on":
+			on = true
+		case "off":
+			on = false
+		default:
+			return h.sendReply(ctx, message, "❌ 请指定 on 或 off\n"+usage)
+		}
+		updated, err := h.mirrors.SetSyncDelete(source, on)
+		if err != nil {
+			log.Printf("保存镜像配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+		if !updated {
+			return h.sendReply(ctx, message, "❌ 镜像配置不存在")
+		}
+		return h.sendReply(ctx, message, "✅ 同步删除: "+onOff(on))
+	}
+
+	return h.sendReply(ctx, message, "❌ 未知操作\n"+usage)
+}
+
+// formatMirrorList 列出用户管理的源频道的镜像配置
+func (h *MessageHandler) formatMirrorList(ctx context.Context, userID int64) string {
+	var b strings.Builder
+	b.WriteString("🪞 频道镜像列表:\n\n")
+
+	count := 0
+	for _, mirror := range h.mirrors.List() {
+		if !h.isUserAdmin(ctx, mirror.SourceChatID, userID) {
+			continue
+		}
+
+		targets := make([]string, len(mirror.Targets))
+		for i, t := range mirror.Targets {
+			targets[i] = strconv.FormatInt(t, 10)
+		}
+		b.WriteString(fmt.Sprintf("%d → %s (同步删除: %s)\n",
+			mirror.SourceChatID, strings.Join(targets, ", "), onOff(mirror.SyncDelete)))
+		count++
+	}
+
+	if count == 0 {
+		return "📭 暂无镜像配置"
+	}
+	return b.String()
+}
+
+// containsInt64 检查切片中是否包含指定值
+func containsInt64(values []int64, v int64) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
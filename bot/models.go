@@ -12,32 +12,120 @@ type ApiResponse struct {
 
 // Update 更新结构
 type Update struct {
-	UpdateID         int               `json:"update_id"`
-	Message          *Message          `json:"message,omitempty"`
-	EditedMessage    *Message          `json:"edited_message,omitempty"`
-	CallbackQuery    *CallbackQuery    `json:"callback_query,omitempty"`
-	ChatJoinRequest  *ChatJoinRequest  `json:"chat_join_request,omitempty"`
+	UpdateID             int                          `json:"update_id"`
+	Message              *Message                     `json:"message,omitempty"`
+	EditedMessage        *Message                     `json:"edited_message,omitempty"`
+	ChannelPost          *Message                     `json:"channel_post,omitempty"`
+	EditedChannelPost    *Message                     `json:"edited_channel_post,omitempty"`
+	InlineQuery          *InlineQuery                 `json:"inline_query,omitempty"`
+	ChosenInlineResult   *ChosenInlineResult          `json:"chosen_inline_result,omitempty"`
+	CallbackQuery        *CallbackQuery               `json:"callback_query,omitempty"`
+	ShippingQuery        *ShippingQuery               `json:"shipping_query,omitempty"`
+	PreCheckoutQuery     *PreCheckoutQuery            `json:"pre_checkout_query,omitempty"`
+	Poll                 *Poll                        `json:"poll,omitempty"`
+	PollAnswer           *PollAnswer                  `json:"poll_answer,omitempty"`
+	MyChatMember         *ChatMemberUpdated           `json:"my_chat_member,omitempty"`
+	ChatMember           *ChatMemberUpdated           `json:"chat_member,omitempty"`
+	ChatJoinRequest      *ChatJoinRequest             `json:"chat_join_request,omitempty"`
+	MessageReaction      *MessageReactionUpdated      `json:"message_reaction,omitempty"`
+	MessageReactionCount *MessageReactionCountUpdated `json:"message_reaction_count,omitempty"`
+}
+
+// 更新类型名称 (用于 getUpdates 的 allowed_updates 参数)
+const (
+	UpdateTypeMessage              = "message"
+	UpdateTypeEditedMessage        = "edited_message"
+	UpdateTypeChannelPost          = "channel_post"
+	UpdateTypeEditedChannelPost    = "edited_channel_post"
+	UpdateTypeInlineQuery          = "inline_query"
+	UpdateTypeChosenInlineResult   = "chosen_inline_result"
+	UpdateTypeCallbackQuery        = "callback_query"
+	UpdateTypeShippingQuery        = "shipping_query"
+	UpdateTypePreCheckoutQuery     = "pre_checkout_query"
+	UpdateTypePoll                 = "poll"
+	UpdateTypePollAnswer           = "poll_answer"
+	UpdateTypeMyChatMember         = "my_chat_member"
+	UpdateTypeChatMember           = "chat_member"
+	UpdateTypeChatJoinRequest      = "chat_join_request"
+	UpdateTypeMessageReaction      = "message_reaction"
+	UpdateTypeMessageReactionCount = "message_reaction_count"
+)
+
+// AllUpdateTypes 所有支持的更新类型
+// chat_member 和 message_reaction 等类型默认不会推送，需要在 allowed_updates 中显式声明
+var AllUpdateTypes = []string{
+	UpdateTypeMessage,
+	UpdateTypeEditedMessage,
+	UpdateTypeChannelPost,
+	UpdateTypeEditedChannelPost,
+	UpdateTypeInlineQuery,
+	UpdateTypeChosenInlineResult,
+	UpdateTypeCallbackQuery,
+	UpdateTypeShippingQuery,
+	UpdateTypePreCheckoutQuery,
+	UpdateTypePoll,
+	UpdateTypePollAnswer,
+	UpdateTypeMyChatMember,
+	UpdateTypeChatMember,
+	UpdateTypeChatJoinRequest,
+	UpdateTypeMessageReaction,
+	UpdateTypeMessageReactionCount,
 }
 
 // Message 消息结构
 type Message struct {
-	MessageID       int              `json:"message_id"`
-	From            *User            `json:"from,omitempty"`
-	Date            int64            `json:"date"`
-	Chat            *Chat            `json:"chat"`
-	ForwardFrom     *User            `json:"forward_from,omitempty"`
-	ForwardDate     int64            `json:"forward_date,omitempty"`
-	ReplyToMessage  *Message         `json:"reply_to_message,omitempty"`
-	Text            string           `json:"text,omitempty"`
-	Entities        []MessageEntity  `json:"entities,omitempty"`
-	Photo           []PhotoSize      `json:"photo,omitempty"`
-	Video           *Video           `json:"video,omitempty"`
-	Document        *Document        `json:"document,omitempty"`
-	Audio           *Audio           `json:"audio,omitempty"`
-	Voice           *Voice           `json:"voice,omitempty"`
-	Caption         string           `json:"caption,omitempty"`
-	Contact         *Contact         `json:"contact,omitempty"`
-	Location        *Location        `json:"location,omitempty"`
+	MessageID          int                   `json:"message_id"`
+	From               *User                 `json:"from,omitempty"`
+	SenderChat         *Chat                 `json:"sender_chat,omitempty"`
+	Date               int64                 `json:"date"`
+	Chat               *Chat                 `json:"chat"`
+	MediaGroupID       string                `json:"media_group_id,omitempty"`
+	ForwardOrigin      *MessageOrigin        `json:"forward_origin,omitempty"`
+	ForwardFrom        *User                 `json:"forward_from,omitempty"`
+	ForwardFromChat    *Chat                 `json:"forward_from_chat,omitempty"`
+	ForwardSenderName  string                `json:"forward_sender_name,omitempty"`
+	ForwardDate        int64                 `json:"forward_date,omitempty"`
+	IsAutomaticForward bool                  `json:"is_automatic_forward,omitempty"` // 关联频道自动转发到讨论组的消息
+	ReplyToMessage     *Message              `json:"reply_to_message,omitempty"`
+	Text               string                `json:"text,omitempty"`
+	Entities           []MessageEntity       `json:"entities,omitempty"`
+	Photo              []PhotoSize           `json:"photo,omitempty"`
+	Video              *Video                `json:"video,omitempty"`
+	Document           *Document             `json:"document,omitempty"`
+	Audio              *Audio                `json:"audio,omitempty"`
+	Voice              *Voice                `json:"voice,omitempty"`
+	Sticker            *Sticker              `json:"sticker,omitempty"`
+	Caption            string                `json:"caption,omitempty"`
+	CaptionEntities    []MessageEntity       `json:"caption_entities,omitempty"`
+	Contact            *Contact              `json:"contact,omitempty"`
+	Location           *Location             `json:"location,omitempty"`
+	Poll               *Poll                 `json:"poll,omitempty"`
+	NewChatMembers     []User                `json:"new_chat_members,omitempty"`
+	LeftChatMember     *User                 `json:"left_chat_member,omitempty"`
+	ReplyMarkup        *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+// IsForwarded 消息是否为转发消息
+func (m *Message) IsForwarded() bool {
+	return m.ForwardOrigin != nil || m.ForwardFrom != nil || m.ForwardFromChat != nil ||
+		m.ForwardSenderName != "" || m.ForwardDate != 0
+}
+
+// MessageOrigin 转发消息的来源信息
+type MessageOrigin struct {
+	Type            string `json:"type"` // user/hidden_user/chat/channel
+	Date            int64  `json:"date"`
+	SenderUser      *User  `json:"sender_user,omitempty"`
+	SenderUserName  string `json:"sender_user_name,omitempty"`
+	SenderChat      *Chat  `json:"sender_chat,omitempty"`
+	Chat            *Chat  `json:"chat,omitempty"`
+	MessageID       int    `json:"message_id,omitempty"`
+	AuthorSignature string `json:"author_signature,omitempty"`
+}
+
+// MessageID 消息ID (copyMessage 等方法的返回值)
+type MessageID struct {
+	MessageID int `json:"message_id"`
 }
 
 // User 用户结构
@@ -62,24 +150,85 @@ type Chat struct {
 	Description                 string           `json:"description,omitempty"`
 	InviteLink                  string           `json:"invite_link,omitempty"`
 	Permissions                 *ChatPermissions `json:"permissions,omitempty"`
+	PinnedMessage               *Message         `json:"pinned_message,omitempty"`
+	LinkedChatID                int64            `json:"linked_chat_id,omitempty"`
 }
 
+// 聊天成员状态
+const (
+	MemberStatusCreator       = "creator"
+	MemberStatusAdministrator = "administrator"
+	MemberStatusMember        = "member"
+	MemberStatusRestricted    = "restricted"
+	MemberStatusLeft          = "left"
+	MemberStatusKicked        = "kicked"
+)
+
 // ChatMember 聊天成员结构
+// 不同状态 (creator/administrator/member/restricted/left/kicked) 使用的字段不同，统一平铺在同一结构中
 type ChatMember struct {
 	User   *User  `json:"user"`
 	Status string `json:"status"`
-}
 
-// ChatPermissions 聊天权限结构
-type ChatPermissions struct {
+	// creator 和 administrator
+	CustomTitle string `json:"custom_title,omitempty"`
+	IsAnonymous bool   `json:"is_anonymous,omitempty"`
+
+	// administrator
+	CanBeEdited         bool `json:"can_be_edited,omitempty"`
+	CanManageChat       bool `json:"can_manage_chat,omitempty"`
+	CanDeleteMessages   bool `json:"can_delete_messages,omitempty"`
+	CanManageVideoChats bool `json:"can_manage_video_chats,omitempty"`
+	CanRestrictMembers  bool `json:"can_restrict_members,omitempty"`
+	CanPromoteMembers   bool `json:"can_promote_members,omitempty"`
+	CanPostMessages     bool `json:"can_post_messages,omitempty"`
+	CanEditMessages     bool `json:"can_edit_messages,omitempty"`
+	CanPostStories      bool `json:"can_post_stories,omitempty"`
+	CanEditStories      bool `json:"can_edit_stories,omitempty"`
+	CanDeleteStories    bool `json:"can_delete_stories,omitempty"`
+	CanManageTopics     bool `json:"can_manage_topics,omitempty"`
+
+	// administrator 和 restricted
+	CanChangeInfo  bool `json:"can_change_info,omitempty"`
+	CanInviteUsers bool `json:"can_invite_users,omitempty"`
+	CanPinMessages bool `json:"can_pin_messages,omitempty"`
+
+	// restricted
+	IsMember              bool `json:"is_member,omitempty"`
 	CanSendMessages       bool `json:"can_send_messages,omitempty"`
-	CanSendMediaMessages  bool `json:"can_send_media_messages,omitempty"`
+	CanSendAudios         bool `json:"can_send_audios,omitempty"`
+	CanSendDocuments      bool `json:"can_send_documents,omitempty"`
+	CanSendPhotos         bool `json:"can_send_photos,omitempty"`
+	CanSendVideos         bool `json:"can_send_videos,omitempty"`
+	CanSendVideoNotes     bool `json:"can_send_video_notes,omitempty"`
+	CanSendVoiceNotes     bool `json:"can_send_voice_notes,omitempty"`
 	CanSendPolls          bool `json:"can_send_polls,omitempty"`
 	CanSendOtherMessages  bool `json:"can_send_other_messages,omitempty"`
 	CanAddWebPagePreviews bool `json:"can_add_web_page_previews,omitempty"`
-	CanChangeInfo         bool `json:"can_change_info,omitempty"`
-	CanInviteUsers        bool `json:"can_invite_users,omitempty"`
-	CanPinMessages        bool `json:"can_pin_messages,omitempty"`
+
+	// restricted 和 kicked，0 表示永久
+	UntilDate int64 `json:"until_date,omitempty"`
+}
+
+// ChatPermissions 聊天权限结构
+// 基础权限不使用 omitempty，false 值也会被发送，保证设置和恢复时结果准确；
+// 细分的媒体权限使用指针，未返回的字段在恢复时保持缺省
+type ChatPermissions struct {
+	CanSendMessages       bool  `json:"can_send_messages"`
+	CanSendMediaMessages  bool  `json:"can_send_media_messages"`
+	CanSendAudios         *bool `json:"can_send_audios,omitempty"`
+	CanSendDocuments      *bool `json:"can_send_documents,omitempty"`
+	CanSendPhotos         *bool `json:"can_send_photos,omitempty"`
+	CanSendVideos         *bool `json:"can_send_videos,omitempty"`
+	CanSendVideoNotes     *bool `json:"can_send_video_notes,omitempty"`
+	CanSendVoiceNotes     *bool `json:"can_send_voice_notes,omitempty"`
+	CanSendPolls          bool  `json:"can_send_polls"`
+	CanSendOtherMessages  bool  `json:"can_send_other_messages"`
+	CanAddWebPagePreviews bool  `json:"can_add_web_page_previews"`
+	CanChangeInfo         bool  `json:"can_change_info"`
+	CanInviteUsers        bool  `json:"can_invite_users"`
+	CanPinMessages        bool  `json:"can_pin_messages"`
+	CanManageTopics       *bool `json:"can_manage_topics,omitempty"`
 }
 
 // MessageEntity 消息实体结构
@@ -138,6 +287,26 @@ type Voice struct {
 	FileSize int    `json:"file_size,omitempty"`
 }
 
+// File 文件信息 (getFile 的返回值)
+type File struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileSize     int    `json:"file_size,omitempty"`
+	FilePath     string `json:"file_path,omitempty"`
+}
+
+// Sticker 贴纸结构
+type Sticker struct {
+	FileID     string `json:"file_id"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	IsAnimated bool   `json:"is_animated"`
+	IsVideo    bool   `json:"is_video"`
+	Emoji      string `json:"emoji,omitempty"`
+	SetName    string `json:"set_name,omitempty"`
+	FileSize   int    `json:"file_size,omitempty"`
+}
+
 // Contact 联系人结构
 type Contact struct {
 	PhoneNumber string `json:"phone_number"`
@@ -163,11 +332,159 @@ type CallbackQuery struct {
 
 // ChatJoinRequest 加群请求结构
 type ChatJoinRequest struct {
-	Chat       *Chat `json:"chat"`
-	From       *User `json:"from"`
-	Date       int64 `json:"date"`
-	Bio        string `json:"bio,omitempty"`
-	InviteLink string `json:"invite_link,omitempty"`
+	Chat       *Chat           `json:"chat"`
+	From       *User           `json:"from"`
+	Date       int64           `json:"date"`
+	Bio        string          `json:"bio,omitempty"`
+	InviteLink *ChatInviteLink `json:"invite_link,omitempty"`
+}
+
+// InlineQuery 内联查询结构
+type InlineQuery struct {
+	ID       string    `json:"id"`
+	From     *User     `json:"from"`
+	Query    string    `json:"query"`
+	Offset   string    `json:"offset"`
+	ChatType string    `json:"chat_type,omitempty"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// ChosenInlineResult 用户选择的内联结果
+type ChosenInlineResult struct {
+	ResultID        string    `json:"result_id"`
+	From            *User     `json:"from"`
+	Location        *Location `json:"location,omitempty"`
+	InlineMessageID string    `json:"inline_message_id,omitempty"`
+	Query           string    `json:"query"`
+}
+
+// ShippingAddress 收货地址
+type ShippingAddress struct {
+	CountryCode string `json:"country_code"`
+	State       string `json:"state"`
+	City        string `json:"city"`
+	StreetLine1 string `json:"street_line1"`
+	StreetLine2 string `json:"street_line2"`
+	PostCode    string `json:"post_code"`
+}
+
+// OrderInfo 订单信息
+type OrderInfo struct {
+	Name            string           `json:"name,omitempty"`
+	PhoneNumber     string           `json:"phone_number,omitempty"`
+	Email           string           `json:"email,omitempty"`
+	ShippingAddress *ShippingAddress `json:"shipping_address,omitempty"`
+}
+
+// ShippingQuery 配送查询结构
+type ShippingQuery struct {
+	ID              string           `json:"id"`
+	From            *User            `json:"from"`
+	InvoicePayload  string           `json:"invoice_payload"`
+	ShippingAddress *ShippingAddress `json:"shipping_address"`
+}
+
+// PreCheckoutQuery 支付前确认查询结构
+type PreCheckoutQuery struct {
+	ID               string     `json:"id"`
+	From             *User      `json:"from"`
+	Currency         string     `json:"currency"`
+	TotalAmount      int        `json:"total_amount"`
+	InvoicePayload   string     `json:"invoice_payload"`
+	ShippingOptionID string     `json:"shipping_option_id,omitempty"`
+	OrderInfo        *OrderInfo `json:"order_info,omitempty"`
+}
+
+// PollOption 投票选项
+type PollOption struct {
+	Text       string `json:"text"`
+	VoterCount int    `json:"voter_count"`
+}
+
+// Poll 投票结构
+type Poll struct {
+	ID                    string       `json:"id"`
+	Question              string       `json:"question"`
+	Options               []PollOption `json:"options"`
+	TotalVoterCount       int          `json:"total_voter_count"`
+	IsClosed              bool         `json:"is_closed"`
+	IsAnonymous           bool         `json:"is_anonymous"`
+	Type                  string       `json:"type"`
+	AllowsMultipleAnswers bool         `json:"allows_multiple_answers"`
+	CorrectOptionID       *int         `json:"correct_option_id,omitempty"`
+}
+
+// PollAnswer 非匿名投票的用户答案
+type PollAnswer struct {
+	PollID    string `json:"poll_id"`
+	VoterChat *Chat  `json:"voter_chat,omitempty"`
+	User      *User  `json:"user,omitempty"`
+	OptionIDs []int  `json:"option_ids"`
+}
+
+// ChatInviteLink 邀请链接结构
+type ChatInviteLink struct {
+	InviteLink              string `json:"invite_link"`
+	Creator                 *User  `json:"creator"`
+	CreatesJoinRequest      bool   `json:"creates_join_request"`
+	IsPrimary               bool   `json:"is_primary"`
+	IsRevoked               bool   `json:"is_revoked"`
+	Name                    string `json:"name,omitempty"`
+	ExpireDate              int64  `json:"expire_date,omitempty"`
+	MemberLimit             int    `json:"member_limit,omitempty"`
+	PendingJoinRequestCount int    `json:"pending_join_request_count,omitempty"`
+}
+
+// ChatMemberUpdated 聊天成员状态变更
+type ChatMemberUpdated struct {
+	Chat                    *Chat           `json:"chat"`
+	From                    *User           `json:"from"`
+	Date                    int64           `json:"date"`
+	OldChatMember           *ChatMember     `json:"old_chat_member"`
+	NewChatMember           *ChatMember     `json:"new_chat_member"`
+	InviteLink              *ChatInviteLink `json:"invite_link,omitempty"`
+	ViaChatFolderInviteLink bool            `json:"via_chat_folder_invite_link,omitempty"`
+}
+
+// ReactionType 消息表情回应
+type ReactionType struct {
+	Type          string `json:"type"`
+	Emoji         string `json:"emoji,omitempty"`
+	CustomEmojiID string `json:"custom_emoji_id,omitempty"`
+}
+
+// ReactionCount 某种表情回应的数量
+type ReactionCount struct {
+	Type       ReactionType `json:"type"`
+	TotalCount int          `json:"total_count"`
+}
+
+// MessageReactionUpdated 用户对消息的表情回应变更
+type MessageReactionUpdated struct {
+	Chat        *Chat          `json:"chat"`
+	MessageID   int            `json:"message_id"`
+	User        *User          `json:"user,omitempty"`
+	ActorChat   *Chat          `json:"actor_chat,omitempty"`
+	Date        int64          `json:"date"`
+	OldReaction []ReactionType `json:"old_reaction"`
+	NewReaction []ReactionType `json:"new_reaction"`
+}
+
+// MessageReactionCountUpdated 匿名表情回应数量变更
+type MessageReactionCountUpdated struct {
+	Chat      *Chat           `json:"chat"`
+	MessageID int             `json:"message_id"`
+	Date      int64           `json:"date"`
+	Reactions []ReactionCount `json:"reactions"`
+}
+
+// InputMedia 媒体组中的一项 (使用 file_id 发送，无需重新上传)
+type InputMedia struct {
+	Type            string          `json:"type"`
+	Media           string          `json:"media"`
+	Caption         string          `json:"caption,omitempty"`
+	ParseMode       string          `json:"parse_mode,omitempty"`
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
 }
 
 // InlineKeyboardMarkup 内联键盘标记
@@ -195,4 +512,4 @@ type KeyboardButton struct {
 	Text            string `json:"text"`
 	RequestContact  bool   `json:"request_contact,omitempty"`
 	RequestLocation bool   `json:"request_location,omitempty"`
-} 
\ No newline at end of file
+}
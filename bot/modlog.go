@@ -0,0 +1,454 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MessageLinkBase 生成消息链接使用的基础地址
+const MessageLinkBase = "https://t.me"
+
+// 管理操作类型
+const (
+	ModActionBan         = "ban"
+	ModActionUnban       = "unban"
+	ModActionPromote     = "promote"
+	ModActionDemote      = "demote"
+	ModActionMute        = "mute"
+	ModActionUnmute      = "unmute"
+	ModActionKick        = "kick"
+	ModActionFedBan      = "fban"
+	ModActionFedUnban    = "unfban"
+	ModActionGlobalBan   = "gban"
+	ModActionGlobalUnban = "ungban"
+)
+
+// modActionLabels 管理操作显示名称 (用作日志标签)
+var modActionLabels = map[string]string{
+	ModActionBan:         "封禁",
+	ModActionUnban:       "解封",
+	ModActionPromote:     "提升管理员",
+	ModActionDemote:      "撤销管理员",
+	ModActionMute:        "禁言",
+	ModActionUnmute:      "解除禁言",
+	ModActionKick:        "踢出",
+	ModActionFedBan:      "联邦封禁",
+	ModActionFedUnban:    "联邦解封",
+	ModActionGlobalBan:   "全局封禁",
+	ModActionGlobalUnban: "全局解封",
+}
+
+// modActionRights 执行各管理操作所需的管理员权限
+var modActionRights = map[string]AdminRight{
+	ModActionBan:     RightRestrictMembers,
+	ModActionUnban:   RightRestrictMembers,
+	ModActionPromote: RightPromoteMembers,
+	ModActionDemote:  RightPromoteMembers,
+	ModActionMute:    RightRestrictMembers,
+	ModActionUnmute:  RightRestrictMembers,
+	ModActionKick:    RightRestrictMembers,
+}
+
+// modActionUndo 可撤销的操作及其对应的撤销操作
+var modActionUndo = map[string]string{
+	ModActionBan:     ModActionUnban,
+	ModActionPromote: ModActionDemote,
+	ModActionMute:    ModActionUnmute,
+}
+
+// ModAction 一条管理操作记录
+type ModAction struct {
+	Action   string
+	Chat     *Chat
+	Actor    *User
+	TargetID int64
+	Target   *User // 可能为空，仅知道用户ID时只显示ID
+	Reason   string
+	Duration time.Duration // 0 表示永久
+	Message  *Message      // 触发操作的消息
+}
+
+// modLogCallbackPrefix 日志频道撤销按钮的 callback_data 前缀
+const modLogCallbackPrefix = "modlog:"
+
+// setLogCodeTTL 频道绑定码的有效期
+const setLogCodeTTL = 10 * time.Minute
+
+// pendingLogChannel 等待在群组中确认的日志频道
+type pendingLogChannel struct {
+	ChannelID int64
+	Title     string
+	ExpiresAt time.Time
+}
+
+// logChannelBinder 保存频道中发起的 /setlog 绑定请求
+type logChannelBinder struct {
+	mu      sync.Mutex
+	pending map[string]pendingLogChannel
+}
+
+// newLogChannelBinder 创建日志频道绑定器
+func newLogChannelBinder() *logChannelBinder {
+	return &logChannelBinder{pending: make(map[string]pendingLogChannel)}
+}
+
+// add 为频道生成绑定码
+func (b *logChannelBinder) add(channel *Chat) (string, error) {
+	code, err := randomToken(4)
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for c, p := range b.pending {
+		if now.After(p.ExpiresAt) {
+			delete(b.pending, c)
+		}
+	}
+
+	b.pending[code] = pendingLogChannel{
+		ChannelID: channel.ID,
+		Title:     channel.Title,
+		ExpiresAt: now.Add(setLogCodeTTL),
+	}
+	return code, nil
+}
+
+// take 取出并删除绑定码对应的频道
+func (b *logChannelBinder) take(code string) (pendingLogChannel, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, ok := b.pending[code]
+	delete(b.pending, code)
+	if !ok || time.Now().After(p.ExpiresAt) {
+		return pendingLogChannel{}, false
+	}
+	return p, true
+}
+
+// handleChannelSetLog 处理频道中发送的 /setlog
+func (h *MessageHandler) handleChannelSetLog(ctx context.Context, post *Message) error {
+	code, err := h.logBinder.add(post.Chat)
+	if err != nil {
+		return fmt.Errorf("生成绑定码失败: %w", err)
+	}
+
+	text := fmt.Sprintf("📋 请在需要绑定的群组中发送以下命令完成绑定 (%d分钟内有效):\n\n/setlog %s",
+		int(setLogCodeTTL.Minutes()), code)
+	_, err = h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: post.Chat.ID,
+		Text:   text,
+	})
+	return err
+}
+
+// handleSetLogCommand 处理群组中的 /setlog <绑定码>
+func (h *MessageHandler) handleSetLogCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, "❌ 请先在日志频道中发送 /setlog 获取绑定码\n用法: /setlog <绑定码>")
+	}
+
+	channel, ok := h.logBinder.take(args[0])
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 绑定码无效或已过期")
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		s.LogChannelID = channel.ChannelID
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	_, err = h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: channel.ChannelID,
+		Text:   fmt.Sprintf("✅ 本频道已绑定为群组 %s (%d) 的管理日志频道", message.Chat.Title, message.Chat.ID),
+	})
+	if err != nil {
+		log.Printf("发送绑定通知到日志频道失败: %v", err)
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已绑定日志频道: %s", channel.Title))
+}
+
+// handleUnsetLogCommand 处理 /unsetlog 命令
+func (h *MessageHandler) handleUnsetLogCommand(ctx context.Context, message *Message) error {
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		s.LogChannelID = 0
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	return h.sendReply(ctx, message, "✅ 已解除日志频道绑定")
+}
+
+// punishMember 对成员执行禁言、封禁或踢出并记录到管理日志
+// action.Action 为 ModActionMute/ModActionBan/ModActionKick，禁言和封禁按 action.Duration 计时 (0 表示永久)
+func (h *MessageHandler) punishMember(ctx context.Context, action ModAction) error {
+	chatID := action.Chat.ID
+	var untilDate int64
+	if action.Duration > 0 {
+		untilDate = time.Now().Add(action.Duration).Unix()
+	}
+
+	switch action.Action {
+	case ModActionMute:
+		err := h.client.RestrictChatMember(ctx, RestrictChatMemberParams{
+			ChatID:      chatID,
+			UserID:      action.TargetID,
+			Permissions: &ChatPermissions{},
+			UntilDate:   untilDate,
+		})
+		if err != nil {
+			return err
+		}
+
+	case ModActionBan:
+		if err := h.client.BanChatMember(ctx, BanChatMemberParams{ChatID: chatID, UserID: action.TargetID, UntilDate: untilDate}); err != nil {
+			return err
+		}
+
+	case ModActionKick:
+		if err := h.client.BanChatMember(ctx, BanChatMemberParams{ChatID: chatID, UserID: action.TargetID}); err != nil {
+			return err
+		}
+		if err := h.client.UnbanChatMember(ctx, UnbanChatMemberParams{ChatID: chatID, UserID: action.TargetID, OnlyIfBanned: true}); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unsupported punishment: %s", action.Action)
+	}
+
+	h.logModAction(ctx, action)
+	return nil
+}
+
+// logModAction 将管理操作记录发送到群组绑定的日志频道
+// 发送失败只记录日志，不影响操作本身
+func (h *MessageHandler) logModAction(ctx context.Context, action ModAction) {
+	settings := h.settings.Get(action.Chat.ID)
+	if settings.LogChannelID == 0 || !settings.IsModuleEnabled(ModuleModLog) {
+		return
+	}
+
+	params := SendMessageParams{
+		ChatID:                settings.LogChannelID,
+		Text:                  formatModAction(action),
+		DisableWebPagePreview: true,
+	}
+
+	if _, ok := modActionUndo[action.Action]; ok {
+		params.ReplyMarkup = &InlineKeyboardMarkup{
+			InlineKeyboard: [][]InlineKeyboardButton{{
+				{
+					Text:         "↩️ 撤销",
+					CallbackData: fmt.Sprintf("%sundo:%s:%d:%d", modLogCallbackPrefix, action.Action, action.Chat.ID, action.TargetID),
+				},
+			}},
+		}
+	}
+
+	if _, err := h.client.SendMessage(ctx, params); err != nil {
+		log.Printf("发送管理日志失败: %v", err)
+	}
+}
+
+// formatModAction 格式化管理操作记录
+func formatModAction(action ModAction) string {
+	var b strings.Builder
+
+	label := modActionLabels[action.Action]
+	if label == "" {
+		label = action.Action
+	}
+
+	b.WriteString(fmt.Sprintf("🛡 #%s\n", label))
+	b.WriteString(fmt.Sprintf("群组: %s (%d)\n", action.Chat.Title, action.Chat.ID))
+	if action.Actor != nil {
+		b.WriteString(fmt.Sprintf("操作人: %s (%d)\n", getUserName(action.Actor), action.Actor.ID))
+	}
+	if action.Target != nil {
+		b.WriteString(fmt.Sprintf("对象: %s (%d)\n", getUserName(action.Target), action.TargetID))
+	} else {
+		b.WriteString(fmt.Sprintf("对象: %d\n", action.TargetID))
+	}
+	if action.Reason != "" {
+		b.WriteString(fmt.Sprintf("原因: %s\n", action.Reason))
+	}
+	if action.Action == ModActionBan || action.Action == ModActionMute {
+		b.WriteString(fmt.Sprintf("时长: %s\n", formatDuration(action.Duration)))
+	}
+	if action.Message != nil {
+		if link := messageLink(action.Message.Chat, action.Message.MessageID); link != "" {
+			b.WriteString(fmt.Sprintf("消息: %s\n", link))
+		}
+	}
+	b.WriteString(fmt.Sprintf("时间: %s", time.Now().Format("2006-01-02 15:04:05")))
+
+	return b.String()
+}
+
+// handleModLogCallback 处理日志频道中的撤销按钮
+// data 格式: undo:<操作>:<群组ID>:<用户ID>
+func (h *MessageHandler) handleModLogCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	parts := strings.Split(data, ":")
+	if len(parts) != 4 || parts[0] != "undo" {
+		return h.answerCallback(ctx, query, "", false)
+	}
+
+	chatID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return h.answerCallback(ctx, query, "❌ 无效的群组ID", true)
+	}
+	userID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return h.answerCallback(ctx, query, "❌ 无效的用户ID", true)
+	}
+
+	undo, ok := modActionUndo[parts[1]]
+	if !ok {
+		return h.answerCallback(ctx, query, "❌ 该操作无法撤销", true)
+	}
+
+	right := modActionRights[undo]
+	if !h.memberHasRight(ctx, chatID, query.From.ID, right) {
+		return h.answerCallback(ctx, query, fmt.Sprintf("❌ 您在该群组中没有「%s」权限", adminRightLabels[right]), true)
+	}
+	if !h.botHasRight(ctx, chatID, right) {
+		return h.answerCallback(ctx, query, fmt.Sprintf("❌ Bot在该群组中没有「%s」权限", adminRightLabels[right]), true)
+	}
+
+	switch undo {
+	case ModActionUnban:
+		err = h.client.UnbanChatMember(ctx, UnbanChatMemberParams{
+			ChatID:       chatID,
+			UserID:       userID,
+			OnlyIfBanned: true,
+		})
+	case ModActionDemote:
+		err = h.client.PromoteChatMember(ctx, PromoteChatMemberParams{
+			ChatID: chatID,
+			UserID: userID,
+		})
+	case ModActionUnmute:
+		// 解除禁言即恢复为群组的默认权限
+		var permissions *ChatPermissions
+		permissions, err = h.currentChatPermissions(ctx, chatID)
+		if err == nil {
+			err = h.client.RestrictChatMember(ctx, RestrictChatMemberParams{
+				ChatID:      chatID,
+				UserID:      userID,
+				Permissions: permissions,
+			})
+		}
+	}
+	if err != nil {
+		return h.answerCallback(ctx, query, "❌ 撤销失败: "+err.Error(), true)
+	}
+	if undo == ModActionDemote {
+		h.admins.invalidate(chatID)
+	}
+
+	// 在原日志下标注撤销信息并移除按钮
+	if query.Message != nil {
+		text := query.Message.Text + fmt.Sprintf("\n\n↩️ 已由 %s 撤销", getUserName(query.From))
+		err := h.client.EditMessageText(ctx, EditMessageTextParams{
+			ChatID:                query.Message.Chat.ID,
+			MessageID:             query.Message.MessageID,
+			Text:                  text,
+			DisableWebPagePreview: true,
+		})
+		if err != nil {
+			log.Printf("更新管理日志失败: %v", err)
+		}
+	}
+
+	return h.answerCallback(ctx, query, "✅ 已撤销", false)
+}
+
+// messageLink 生成消息链接
+// 公开群组使用用户名，私有超级群组和频道使用去掉 -100 前缀的ID；
+// 普通群组的消息没有链接，返回空字符串
+func messageLink(chat *Chat, messageID int) string {
+	if chat == nil {
+		return ""
+	}
+
+	if chat.Username != "" {
+		return fmt.Sprintf("%s/%s/%d", MessageLinkBase, chat.Username, messageID)
+	}
+
+	id := strconv.FormatInt(chat.ID, 10)
+	if !strings.HasPrefix(id, "-100") {
+		return ""
+	}
+	return fmt.Sprintf("%s/c/%s/%d", MessageLinkBase, strings.TrimPrefix(id, "-100"), messageID)
+}
+
+// parseDuration 解析时长参数，例如 30s、10m、2h、7d、1w
+func parseDuration(s string) (time.Duration, bool) {
+	if len(s) < 2 {
+		return 0, false
+	}
+
+	units := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+
+	unit, ok := units[s[len(s)-1]]
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(n) * unit, true
+}
+
+// formatDuration 格式化时长，0 表示永久
+func formatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "永久"
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d天", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d小时", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%d分钟", d/time.Minute)
+	default:
+		return fmt.Sprintf("%d秒", d/time.Second)
+	}
+}
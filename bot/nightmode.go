@@ -0,0 +1,308 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	// 内置时区数据库，在没有系统时区数据的容器中也能解析时区
+	_ "time/tzdata"
+)
+
+// nightModeCheckInterval 检查夜间模式切换的间隔
+const nightModeCheckInterval = time.Minute
+
+// defaultNightModeTimezone 未指定时区时使用的默认时区
+const defaultNightModeTimezone = "Asia/Shanghai"
+
+// NightModeSettings 夜间模式配置
+type NightModeSettings struct {
+	Enabled  bool     `json:"enabled"`
+	Start    string   `json:"start"`          // 开始时间 HH:MM
+	End      string   `json:"end"`            // 结束时间 HH:MM
+	Timezone string   `json:"timezone"`       // IANA 时区名，例如 Asia/Shanghai
+	Drop     []string `json:"drop,omitempty"` // 夜间关闭的权限，为空时禁止发送任何内容
+
+	// 夜间模式生效中时保存的原有权限，结束时按原样恢复
+	Active           bool             `json:"active,omitempty"`
+	SavedPermissions *ChatPermissions `json:"saved_permissions,omitempty"`
+}
+
+// clone 复制夜间模式配置
+func (n *NightModeSettings) clone() *NightModeSettings {
+	c := *n
+	c.Drop = append([]string(nil), n.Drop...)
+	if n.SavedPermissions != nil {
+		saved := *n.SavedPermissions
+		c.SavedPermissions = &saved
+	}
+	return &c
+}
+
+// parseClock 解析 HH:MM 格式的时间，返回当天的分钟数
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// inNightWindow 检查当前时间是否处于夜间模式时段 (支持跨越午夜)
+func (n *NightModeSettings) inNightWindow(now time.Time) bool {
+	start, ok1 := parseClock(n.Start)
+	end, ok2 := parseClock(n.End)
+	if !ok1 || !ok2 || start == end {
+		return false
+	}
+
+	loc, err := time.LoadLocation(n.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// nightPermissions 返回夜间使用的权限
+func (n *NightModeSettings) nightPermissions(current *ChatPermissions) *ChatPermissions {
+	if len(n.Drop) == 0 {
+		return readOnlyPermissions(current)
+	}
+
+	values := permissionValues(current)
+	for _, key := range n.Drop {
+		info, ok := findChatPermission(key)
+		if !ok {
+			continue
+		}
+		values[info.Field] = false
+		if key == "media" {
+			for _, field := range mediaPermissionFields {
+				if _, ok := values[field]; ok {
+					values[field] = false
+				}
+			}
+		}
+	}
+	return permissionsFromValues(values)
+}
+
+// checkNightModes 定时任务: 在夜间模式时段的边界切换群组权限
+func (h *MessageHandler) checkNightModes(ctx context.Context) {
+	// 定时任务和命令都会触发检查，串行执行避免重复保存权限
+	h.nightModeMu.Lock()
+	defer h.nightModeMu.Unlock()
+
+	now := time.Now()
+
+	for _, s := range h.settings.List() {
+		night := s.NightMode
+		if night == nil {
+			continue
+		}
+
+		inWindow := night.Enabled && night.inNightWindow(now)
+		switch {
+		case inWindow && !night.Active && s.ReadOnly:
+			// 只读模式保存着白天的权限，夜间模式等只读模式关闭后再生效，避免两份保存的权限互相覆盖
+		case inWindow && !night.Active:
+			if err := h.startNightMode(ctx, s.ChatID, night); err != nil {
+				log.Printf("群组 %d 开启夜间模式失败: %v", s.ChatID, err)
+			}
+		case !inWindow && night.Active:
+			if err := h.endNightMode(ctx, s.ChatID, night); err != nil {
+				log.Printf("群组 %d 结束夜间模式失败: %v", s.ChatID, err)
+			}
+		}
+	}
+}
+
+// startNightMode 保存当前权限并切换到夜间权限
+func (h *MessageHandler) startNightMode(ctx context.Context, chatID int64, night *NightModeSettings) error {
+	current, err := h.currentChatPermissions(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.setChatPermissions(ctx, chatID, night.nightPermissions(current)); err != nil {
+		return err
+	}
+
+	// 保存失败时回滚权限，否则下次检查会把夜间权限当作原有权限保存
+	_, err = h.settings.Update(chatID, func(s *ChatSettings) {
+		if s.NightMode != nil {
+			s.NightMode.Active = true
+			s.NightMode.SavedPermissions = current
+		}
+	})
+	if err != nil {
+		if rollbackErr := h.setChatPermissions(ctx, chatID, current); rollbackErr != nil {
+			log.Printf("群组 %d 回滚夜间模式权限失败: %v", chatID, rollbackErr)
+		}
+		return fmt.Errorf("保存群组配置失败: %w", err)
+	}
+
+	_, err = h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: chatID,
+		Text:   fmt.Sprintf("🌙 夜间模式已开启，群组将于 %s (%s) 恢复正常", night.End, night.Timezone),
+	})
+	return err
+}
+
+// endNightMode 恢复夜间模式开启前的权限
+func (h *MessageHandler) endNightMode(ctx context.Context, chatID int64, night *NightModeSettings) error {
+	if night.SavedPermissions != nil {
+		if err := h.setChatPermissions(ctx, chatID, night.SavedPermissions); err != nil {
+			return err
+		}
+	}
+
+	_, err := h.settings.Update(chatID, func(s *ChatSettings) {
+		if s.NightMode != nil {
+			s.NightMode.Active = false
+			s.NightMode.SavedPermissions = nil
+		}
+	})
+	if err != nil {
+		// 仍标记为生效中，下次检查时会重试，此时不发送结束通知
+		return fmt.Errorf("保存群组配置失败: %w", err)
+	}
+
+	_, err = h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: chatID,
+		Text:   "☀️ 夜间模式已结束，群组已恢复正常",
+	})
+	return err
+}
+
+// handleNightModeCommand 处理 /nightmode 命令
+// 用法: /nightmode on <开始> <结束> [时区] | /nightmode off | /nightmode drop <权限...>
+func (h *MessageHandler) handleNightModeCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightRestrictMembers); !ok {
+		return err
+	}
+
+	chatID := message.Chat.ID
+	usage := "❌ 用法:\n/nightmode on <开始时间> <结束时间> [时区] - 例如 /nightmode on 00:00 07:00 Asia/Shanghai\n/nightmode off\n/nightmode drop <权限...> - 夜间关闭的权限，all 表示禁止发送任何内容"
+
+	if len(args) == 0 {
+		night := h.settings.Get(chatID).NightMode
+		if night == nil || !night.Enabled {
+			return h.sendReply(ctx, message, "🌙 夜间模式: 关\n\n"+strings.TrimPrefix(usage, "❌ "))
+		}
+
+		drop := "全部发言权限"
+		if len(night.Drop) > 0 {
+			drop = strings.Join(night.Drop, ", ")
+		}
+		status := "未生效"
+		if night.Active {
+			status = "生效中"
+		}
+		return h.sendReply(ctx, message, fmt.Sprintf("🌙 夜间模式: 开 (%s)\n时段: %s - %s\n时区: %s\n关闭权限: %s",
+			status, night.Start, night.End, night.Timezone, drop))
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		if len(args) < 3 {
+			return h.sendReply(ctx, message, usage)
+		}
+		if _, ok := parseClock(args[1]); !ok {
+			return h.sendReply(ctx, message, "❌ 无效的开始时间，格式为 HH:MM")
+		}
+		if _, ok := parseClock(args[2]); !ok {
+			return h.sendReply(ctx, message, "❌ 无效的结束时间，格式为 HH:MM")
+		}
+		if args[1] == args[2] {
+			return h.sendReply(ctx, message, "❌ 开始时间和结束时间不能相同")
+		}
+
+		timezone := defaultNightModeTimezone
+		if len(args) >= 4 {
+			timezone = args[3]
+		}
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return h.sendReply(ctx, message, "❌ 无效的时区: "+timezone)
+		}
+
+		_, err := h.settings.Update(chatID, func(s *ChatSettings) {
+			if s.NightMode == nil {
+				s.NightMode = &NightModeSettings{}
+			}
+			s.NightMode.Enabled = true
+			s.NightMode.Start = args[1]
+			s.NightMode.End = args[2]
+			s.NightMode.Timezone = timezone
+		})
+		if err != nil {
+			log.Printf("保存群组配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+
+		// 立即检查一次，当前已处于夜间时段时马上生效
+		h.checkNightModes(ctx)
+		return h.sendReply(ctx, message, fmt.Sprintf("✅ 已开启夜间模式: 每天 %s - %s (%s)", args[1], args[2], timezone))
+
+	case "off":
+		_, err := h.settings.Update(chatID, func(s *ChatSettings) {
+			if s.NightMode != nil {
+				s.NightMode.Enabled = false
+			}
+		})
+		if err != nil {
+			log.Printf("保存群组配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+
+		// 夜间模式生效中时立即恢复权限
+		h.checkNightModes(ctx)
+		return h.sendReply(ctx, message, "✅ 已关闭夜间模式")
+
+	case "drop":
+		if len(args) < 2 {
+			return h.sendReply(ctx, message, usage)
+		}
+
+		var drop []string
+		if strings.ToLower(args[1]) != "all" {
+			for _, arg := range args[1:] {
+				key := strings.ToLower(arg)
+				if _, ok := findChatPermission(key); !ok {
+					return h.sendReply(ctx, message, "❌ 未知的权限: "+arg)
+				}
+				drop = append(drop, key)
+			}
+		}
+
+		_, err := h.settings.Update(chatID, func(s *ChatSettings) {
+			if s.NightMode == nil {
+				s.NightMode = &NightModeSettings{Timezone: defaultNightModeTimezone}
+			}
+			s.NightMode.Drop = drop
+		})
+		if err != nil {
+			log.Printf("保存群组配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+
+		if len(drop) == 0 {
+			return h.sendReply(ctx, message, "✅ 夜间模式将禁止发送任何内容")
+		}
+		return h.sendReply(ctx, message, "✅ 夜间模式将关闭权限: "+strings.Join(drop, ", "))
+	}
+
+	return h.sendReply(ctx, message, usage)
+}
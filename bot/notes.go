@@ -0,0 +1,550 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf16"
+)
+
+// 笔记名称限制: 只允许字母、数字和下划线 (包括中文)，以便用 #名称 调用
+const (
+	maxNoteNameLength = 32
+	noteNameChars     = `\p{L}\p{N}_`
+)
+
+var noteNamePattern = regexp.MustCompile(`^[` + noteNameChars + `]+$`)
+
+// noteHashtagPattern 消息开头的 #名称，名称后的标点 (如 "#rules，") 不属于名称
+var noteHashtagPattern = regexp.MustCompile(`^#([` + noteNameChars + `]+)`)
+
+// buttonPattern 文本中的按钮语法: [文字](buttonurl://链接)，链接后加 :same 表示与上一个按钮同一行
+var buttonPattern = regexp.MustCompile(`\[([^\[\]]+)\]\(buttonurl://([^)\s]+?)(:same)?\)`)
+
+// Note 一条保存的笔记
+// 媒体只保存 file_id，发送时无需重新上传；文本保留格式实体
+type Note struct {
+	Name      string                   `json:"name"`
+	Type      string                   `json:"type"` // text 或媒体类型 (photo/video/document/audio/voice/sticker)
+	FileID    string                   `json:"file_id,omitempty"`
+	Text      string                   `json:"text,omitempty"` // 文本或媒体说明
+	Entities  []MessageEntity          `json:"entities,omitempty"`
+	Buttons   [][]InlineKeyboardButton `json:"buttons,omitempty"`
+	CreatedBy int64                    `json:"created_by"`
+	CreatedAt int64                    `json:"created_at"`
+}
+
+// noteTypeText 纯文本笔记
+const noteTypeText = "text"
+
+// noteTypeLabels 笔记类型显示名称
+var noteTypeLabels = map[string]string{
+	noteTypeText:      "文本",
+	MediaTypePhoto:    "图片",
+	MediaTypeVideo:    "视频",
+	MediaTypeDocument: "文件",
+	MediaTypeAudio:    "音频",
+	MediaTypeVoice:    "语音",
+	MediaTypeSticker:  "贴纸",
+}
+
+// NoteManager 管理各聊天的笔记
+type NoteManager struct {
+	mu      sync.RWMutex
+	storage *Storage
+	chats   map[int64]map[string]*Note // 聊天 -> 小写名称 -> 笔记
+}
+
+// NewNoteManager 创建笔记管理器并从存储中加载
+func NewNoteManager(storage *Storage) (*NoteManager, error) {
+	m := &NoteManager{
+		storage: storage,
+		chats:   make(map[int64]map[string]*Note),
+	}
+
+	if err := storage.Load("notes", &m.chats); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Get 按名称获取笔记 (忽略大小写)
+func (m *NoteManager) Get(chatID int64, name string) (Note, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	note, ok := m.chats[chatID][strings.ToLower(name)]
+	if !ok {
+		return Note{}, false
+	}
+	return *note, true
+}
+
+// Save 保存笔记，同名笔记会被覆盖，返回是否覆盖了原有笔记
+func (m *NoteManager) Save(chatID int64, note Note) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	notes, ok := m.chats[chatID]
+	if !ok {
+		notes = make(map[string]*Note)
+		m.chats[chatID] = notes
+	}
+
+	key := strings.ToLower(note.Name)
+	_, existed := notes[key]
+	notes[key] = &note
+
+	return existed, m.storage.Save("notes", m.chats)
+}
+
+// Delete 删除笔记
+func (m *NoteManager) Delete(chatID int64, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := strings.ToLower(name)
+	if _, ok := m.chats[chatID][key]; !ok {
+		return fmt.Errorf("笔记 #%s 不存在", name)
+	}
+	delete(m.chats[chatID], key)
+	if len(m.chats[chatID]) == 0 {
+		delete(m.chats, chatID)
+	}
+
+	return m.storage.Save("notes", m.chats)
+}
+
+// List 列出聊天的所有笔记 (按名称排序)
+func (m *NoteManager) List(chatID int64) []Note {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	notes := make([]Note, 0, len(m.chats[chatID]))
+	for _, note := range m.chats[chatID] {
+		notes = append(notes, *note)
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Name < notes[j].Name
+	})
+	return notes
+}
+
+// textEdit 对文本的一处替换，start 和 end 为字节偏移
+type textEdit struct {
+	start, end  int
+	replacement string
+}
+
+// utf16Len 返回字符串的 UTF-16 编码长度 (消息实体的偏移量以此计算)
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// applyTextEdits 按顺序替换文本中的若干片段并同步调整格式实体
+// edits 必须按位置排序且互不重叠；与替换片段部分重叠的实体会被丢弃
+func applyTextEdits(text string, entities []MessageEntity, edits []textEdit) (string, []MessageEntity) {
+	type shift struct {
+		oldStart, oldEnd, delta int
+	}
+
+	var b strings.Builder
+	var shifts []shift
+	last := 0
+	for _, e := range edits {
+		b.WriteString(text[last:e.start])
+		b.WriteString(e.replacement)
+		last = e.end
+
+		oldStart := utf16Len(text[:e.start])
+		oldEnd := utf16Len(text[:e.end])
+		shifts = append(shifts, shift{oldStart, oldEnd, utf16Len(e.replacement) - (oldEnd - oldStart)})
+	}
+	b.WriteString(text[last:])
+
+	var adjusted []MessageEntity
+	for _, entity := range entities {
+		start, end := entity.Offset, entity.Offset+entity.Length
+		newStart, newEnd := start, end
+		dropped := false
+		for _, s := range shifts {
+			switch {
+			case s.oldEnd <= start:
+				newStart += s.delta
+				newEnd += s.delta
+			case s.oldStart >= end:
+			case s.oldStart >= start && s.oldEnd <= end:
+				newEnd += s.delta
+			default:
+				dropped = true
+			}
+		}
+		if dropped || newEnd <= newStart {
+			continue
+		}
+		entity.Offset = newStart
+		entity.Length = newEnd - newStart
+		adjusted = append(adjusted, entity)
+	}
+	return b.String(), adjusted
+}
+
+// trimEntityText 去掉文本首尾的空白并同步调整格式实体
+func trimEntityText(text string, entities []MessageEntity) (string, []MessageEntity) {
+	lead := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+	if lead > 0 {
+		text, entities = applyTextEdits(text, entities, []textEdit{{start: 0, end: lead}})
+	}
+
+	text = strings.TrimRightFunc(text, unicode.IsSpace)
+	total := utf16Len(text)
+
+	var trimmed []MessageEntity
+	for _, entity := range entities {
+		if entity.Offset >= total {
+			continue
+		}
+		if entity.Offset+entity.Length > total {
+			entity.Length = total - entity.Offset
+		}
+		trimmed = append(trimmed, entity)
+	}
+	return text, trimmed
+}
+
+// parseButtons 取出文本中的按钮语法，返回去掉按钮后的文本、调整后的实体和按钮
+func parseButtons(text string, entities []MessageEntity) (string, []MessageEntity, [][]InlineKeyboardButton) {
+	matches := buttonPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text, entities, nil
+	}
+
+	var rows [][]InlineKeyboardButton
+	var edits []textEdit
+	for _, m := range matches {
+		button := InlineKeyboardButton{
+			Text: text[m[2]:m[3]],
+			URL:  text[m[4]:m[5]],
+		}
+		sameRow := m[6] >= 0
+		if sameRow && len(rows) > 0 {
+			rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		} else {
+			rows = append(rows, []InlineKeyboardButton{button})
+		}
+		edits = append(edits, textEdit{start: m[0], end: m[1]})
+	}
+
+	text, entities = applyTextEdits(text, entities, edits)
+	text, entities = trimEntityText(text, entities)
+	return text, entities, rows
+}
+
+// urlButtons 取出消息自带键盘中的链接按钮 (回调按钮无法在其他消息中使用)
+func urlButtons(markup *InlineKeyboardMarkup) [][]InlineKeyboardButton {
+	if markup == nil {
+		return nil
+	}
+
+	var rows [][]InlineKeyboardButton
+	for _, row := range markup.InlineKeyboard {
+		var kept []InlineKeyboardButton
+		for _, button := range row {
+			if button.URL != "" {
+				kept = append(kept, button)
+			}
+		}
+		if len(kept) > 0 {
+			rows = append(rows, kept)
+		}
+	}
+	return rows
+}
+
+// buttonsMarkup 生成按钮键盘，没有按钮时返回 nil
+func buttonsMarkup(rows [][]InlineKeyboardButton) interface{} {
+	if len(rows) == 0 {
+		return nil
+	}
+	return &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// noteFromMessage 根据消息内容生成笔记，不支持的消息类型返回错误
+func noteFromMessage(message *Message) (Note, error) {
+	note := Note{
+		Text:     message.Caption,
+		Entities: message.CaptionEntities,
+	}
+
+	switch {
+	case message.Text != "":
+		note.Type = noteTypeText
+		note.Text = message.Text
+		note.Entities = message.Entities
+	case len(message.Photo) > 0:
+		note.Type = MediaTypePhoto
+		note.FileID = message.Photo[len(message.Photo)-1].FileID
+	case message.Video != nil:
+		note.Type = MediaTypeVideo
+		note.FileID = message.Video.FileID
+	case message.Document != nil:
+		note.Type = MediaTypeDocument
+		note.FileID = message.Document.FileID
+	case message.Audio != nil:
+		note.Type = MediaTypeAudio
+		note.FileID = message.Audio.FileID
+	case message.Voice != nil:
+		note.Type = MediaTypeVoice
+		note.FileID = message.Voice.FileID
+	case message.Sticker != nil:
+		note.Type = MediaTypeSticker
+		note.FileID = message.Sticker.FileID
+	default:
+		return Note{}, fmt.Errorf("不支持的消息类型")
+	}
+
+	var buttons [][]InlineKeyboardButton
+	note.Text, note.Entities, buttons = parseButtons(note.Text, note.Entities)
+	note.Buttons = append(urlButtons(message.ReplyMarkup), buttons...)
+
+	if note.Type == noteTypeText && note.Text == "" {
+		return Note{}, fmt.Errorf("笔记内容不能为空")
+	}
+	return note, nil
+}
+
+// sendNote 发送笔记
+func (h *MessageHandler) sendNote(ctx context.Context, chatID int64, note Note, replyTo int) error {
+	if note.Type == noteTypeText {
+		_, err := h.client.SendMessage(ctx, SendMessageParams{
+			ChatID:           chatID,
+			Text:             note.Text,
+			Entities:         note.Entities,
+			ReplyToMessageID: replyTo,
+			ReplyMarkup:      buttonsMarkup(note.Buttons),
+		})
+		return err
+	}
+
+	_, err := h.client.SendMedia(ctx, SendMediaParams{
+		ChatID:           chatID,
+		Type:             note.Type,
+		FileID:           note.FileID,
+		Caption:          note.Text,
+		CaptionEntities:  note.Entities,
+		ReplyToMessageID: replyTo,
+		ReplyMarkup:      buttonsMarkup(note.Buttons),
+	})
+	return err
+}
+
+// deliverNote 在群组中发送笔记，开启私聊发送时改为私聊发给请求者
+func (h *MessageHandler) deliverNote(ctx context.Context, message *Message, note Note) error {
+	if h.settings.Get(message.Chat.ID).PrivateNotes && message.From != nil && message.SenderChat == nil {
+		if err := h.sendNote(ctx, message.From.ID, note, 0); err != nil {
+			log.Printf("私聊发送笔记失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 无法私聊发送笔记，请先私聊启动Bot")
+		}
+		return h.sendReply(ctx, message, fmt.Sprintf("📬 笔记 #%s 已私聊发送给您", note.Name))
+	}
+
+	// 回复他人消息调用笔记时，笔记回复到被回复的消息
+	replyTo := message.MessageID
+	if message.ReplyToMessage != nil {
+		replyTo = message.ReplyToMessage.MessageID
+	}
+	return h.sendNote(ctx, message.Chat.ID, note, replyTo)
+}
+
+// validNoteName 检查笔记名称是否可以用 #名称 调用
+func validNoteName(name string) bool {
+	return len([]rune(name)) <= maxNoteNameLength && noteNamePattern.MatchString(name)
+}
+
+// handleSaveCommand 处理 /save <名称> [内容] 命令，回复消息时保存被回复的消息
+func (h *MessageHandler) handleSaveCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	usage := "❌ 用法: 回复消息发送 /save <名称>，或 /save <名称> <内容>\n按钮语法: [文字](buttonurl://链接)，链接后加 :same 与上一个按钮同行"
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, usage)
+	}
+
+	name := strings.TrimPrefix(args[0], "#")
+	if !validNoteName(name) {
+		return h.sendReply(ctx, message, fmt.Sprintf("❌ 笔记名称只能包含文字、数字和下划线，最多 %d 个字符", maxNoteNameLength))
+	}
+
+	var note Note
+	var err error
+	if content := commandRemainder(message.Text, 2); content != "" {
+		// 命令中直接给出的内容: 去掉命令和名称，保留内容部分的格式
+		start := len(strings.TrimRightFunc(message.Text, unicode.IsSpace)) - len(content)
+		text, entities := applyTextEdits(message.Text, message.Entities, []textEdit{{start: 0, end: start}})
+		note, err = noteFromMessage(&Message{Text: text, Entities: entities})
+	} else if message.ReplyToMessage != nil {
+		note, err = noteFromMessage(message.ReplyToMessage)
+	} else {
+		return h.sendReply(ctx, message, usage)
+	}
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 保存笔记失败: "+err.Error())
+	}
+
+	note.Name = name
+	if message.From != nil {
+		note.CreatedBy = message.From.ID
+	}
+	note.CreatedAt = time.Now().Unix()
+
+	replaced, err := h.notes.Save(message.Chat.ID, note)
+	if err != nil {
+		log.Printf("保存笔记失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存笔记失败")
+	}
+
+	if replaced {
+		return h.sendReply(ctx, message, fmt.Sprintf("✅ 已更新笔记 #%s", name))
+	}
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已保存笔记 #%s，发送 #%s 或 /get %s 即可调用", name, name, name))
+}
+
+// handleGetCommand 处理 /get <名称> 命令
+func (h *MessageHandler) handleGetCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, "❌ 用法: /get <名称>")
+	}
+
+	name := strings.TrimPrefix(args[0], "#")
+	note, ok := h.notes.Get(message.Chat.ID, name)
+	if !ok {
+		return h.sendReply(ctx, message, fmt.Sprintf("❌ 笔记 #%s 不存在，发送 /notes 查看所有笔记", name))
+	}
+
+	return h.deliverNote(ctx, message, note)
+}
+
+// checkNoteHashtag 以 #名称 开头的消息调用对应的笔记，返回是否已调用
+func (h *MessageHandler) checkNoteHashtag(ctx context.Context, message *Message) bool {
+	if message.Chat.Type == "private" {
+		return false
+	}
+
+	match := noteHashtagPattern.FindStringSubmatch(message.Text)
+	if match == nil {
+		return false
+	}
+	name := match[1]
+
+	note, ok := h.notes.Get(message.Chat.ID, name)
+	if !ok {
+		return false
+	}
+
+	if err := h.deliverNote(ctx, message, note); err != nil {
+		log.Printf("发送笔记失败: %v", err)
+	}
+	return true
+}
+
+// handleNotesCommand 处理 /notes 命令，列出本群的笔记
+func (h *MessageHandler) handleNotesCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	notes := h.notes.List(message.Chat.ID)
+	if len(notes) == 0 {
+		return h.sendReply(ctx, message, "📭 本群还没有笔记\n管理员可以回复消息发送 /save <名称> 保存")
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📒 本群笔记 (%d):\n\n", len(notes)))
+	for _, note := range notes {
+		b.WriteString(fmt.Sprintf("• #%s (%s)\n", note.Name, noteTypeLabels[note.Type]))
+	}
+	b.WriteString("\n发送 #名称 或 /get <名称> 调用笔记")
+	if h.settings.Get(message.Chat.ID).PrivateNotes {
+		b.WriteString("，笔记将私聊发送")
+	}
+
+	return h.sendReply(ctx, message, b.String())
+}
+
+// handleClearCommand 处理 /clear <名称> 命令，删除笔记
+func (h *MessageHandler) handleClearCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, "❌ 用法: /clear <名称>")
+	}
+
+	name := strings.TrimPrefix(args[0], "#")
+	if err := h.notes.Delete(message.Chat.ID, name); err != nil {
+		return h.sendReply(ctx, message, "❌ "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已删除笔记 #%s", name))
+}
+
+// handlePrivateNotesCommand 处理 /privatenotes on|off 命令，设置笔记是否私聊发送给请求者
+func (h *MessageHandler) handlePrivateNotesCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	if len(args) == 0 {
+		private := h.settings.Get(message.Chat.ID).PrivateNotes
+		return h.sendReply(ctx, message, fmt.Sprintf("📬 笔记私聊发送: %s\n用法: /privatenotes on|off", onOff(private)))
+	}
+
+	var private bool
+	switch strings.ToLower(args[0]) {
+	case "on":
+		private = true
+	case "off":
+		private = false
+	default:
+		return h.sendReply(ctx, message, "❌ 用法: /privatenotes on|off")
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		s.PrivateNotes = private
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	if private {
+		return h.sendReply(ctx, message, "✅ 笔记将私聊发送给请求者 (成员需先私聊启动Bot)")
+	}
+	return h.sendReply(ctx, message, "✅ 笔记将直接发送到群组")
+}
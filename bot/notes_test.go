@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyTextEdits(t *testing.T) {
+	bold := func(offset, length int) MessageEntity {
+		return MessageEntity{Type: "bold", Offset: offset, Length: length}
+	}
+
+	tests := []struct {
+		name         string
+		text         string
+		entities     []MessageEntity
+		edits        []textEdit
+		wantText     string
+		wantEntities []MessageEntity
+	}{
+		{
+			name:         "entity before edit unchanged",
+			text:         "ab cd",
+			entities:     []MessageEntity{bold(0, 2)},
+			edits:        []textEdit{{start: 3, end: 5, replacement: "xyz"}},
+			wantText:     "ab xyz",
+			wantEntities: []MessageEntity{bold(0, 2)},
+		},
+		{
+			name:         "entity after edit shifts",
+			text:         "/save n hello",
+			entities:     []MessageEntity{bold(8, 5)},
+			edits:        []textEdit{{start: 0, end: 8}},
+			wantText:     "hello",
+			wantEntities: []MessageEntity{bold(0, 5)},
+		},
+		{
+			name:         "edit inside entity resizes it",
+			text:         "hi {first}!",
+			entities:     []MessageEntity{bold(0, 11)},
+			edits:        []textEdit{{start: 3, end: 10, replacement: "Bob"}},
+			wantText:     "hi Bob!",
+			wantEntities: []MessageEntity{bold(0, 7)},
+		},
+		{
+			name:     "partial overlap drops entity",
+			text:     "abcdef",
+			entities: []MessageEntity{bold(2, 3)},
+			edits:    []textEdit{{start: 0, end: 3}},
+			wantText: "def",
+		},
+		{
+			name:     "entity fully removed is dropped",
+			text:     "abc def",
+			entities: []MessageEntity{bold(4, 3)},
+			edits:    []textEdit{{start: 3, end: 7}},
+			wantText: "abc",
+		},
+		{
+			name:         "emoji counts as two UTF-16 units",
+			text:         "😀 ab",
+			entities:     []MessageEntity{bold(3, 2)},
+			edits:        []textEdit{{start: 0, end: len("😀 ")}},
+			wantText:     "ab",
+			wantEntities: []MessageEntity{bold(0, 2)},
+		},
+		{
+			name:         "emoji replacement grows offsets",
+			text:         "{x} ab",
+			entities:     []MessageEntity{bold(4, 2)},
+			edits:        []textEdit{{start: 0, end: 3, replacement: "👍👍"}},
+			wantText:     "👍👍 ab",
+			wantEntities: []MessageEntity{bold(5, 2)},
+		},
+		{
+			name:         "CJK counts as one UTF-16 unit",
+			text:         "你好 世界",
+			entities:     []MessageEntity{bold(3, 2)},
+			edits:        []textEdit{{start: 0, end: len("你好"), replacement: "大家好"}},
+			wantText:     "大家好 世界",
+			wantEntities: []MessageEntity{bold(4, 2)},
+		},
+		{
+			name:         "multiple edits accumulate",
+			text:         "{a}-{b}-end",
+			entities:     []MessageEntity{bold(8, 3)},
+			edits:        []textEdit{{start: 0, end: 3, replacement: "x"}, {start: 4, end: 7, replacement: "yyyy"}},
+			wantText:     "x-yyyy-end",
+			wantEntities: []MessageEntity{bold(7, 3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, entities := applyTextEdits(tt.text, tt.entities, tt.edits)
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			if !reflect.DeepEqual(entities, tt.wantEntities) {
+				t.Errorf("entities = %+v, want %+v", entities, tt.wantEntities)
+			}
+		})
+	}
+}
+
+func TestTrimEntityText(t *testing.T) {
+	text, entities := trimEntityText("  😀ab  ", []MessageEntity{{Type: "bold", Offset: 2, Length: 6}})
+	if text != "😀ab" {
+		t.Errorf("text = %q, want %q", text, "😀ab")
+	}
+	want := []MessageEntity{{Type: "bold", Offset: 0, Length: 4}}
+	if !reflect.DeepEqual(entities, want) {
+		t.Errorf("entities = %+v, want %+v", entities, want)
+	}
+}
+
+func TestNoteHashtagPattern(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"#rules", "rules"},
+		{"#rules, please", "rules"},
+		{"#规则。", "规则"},
+		{"#faq_2!", "faq_2"},
+		{"#", ""},
+		{"# rules", ""},
+		{"see #rules", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if match := noteHashtagPattern.FindStringSubmatch(tt.text); match != nil {
+			got = match[1]
+		}
+		if got != tt.want {
+			t.Errorf("hashtag in %q = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// chatPermissionInfo 可通过 /setperm 修改的群组权限
+type chatPermissionInfo struct {
+	Key   string // 命令中使用的名称
+	Field string // ChatPermissions 的 JSON 字段名
+	Label string
+}
+
+// chatPermissionList 群组权限列表 (按显示顺序)
+var chatPermissionList = []chatPermissionInfo{
+	{Key: "messages", Field: "can_send_messages", Label: "发送消息"},
+	{Key: "media", Field: "can_send_media_messages", Label: "发送媒体"},
+	{Key: "audios", Field: "can_send_audios", Label: "发送音频"},
+	{Key: "documents", Field: "can_send_documents", Label: "发送文件"},
+	{Key: "photos", Field: "can_send_photos", Label: "发送图片"},
+	{Key: "videos", Field: "can_send_videos", Label: "发送视频"},
+	{Key: "videonotes", Field: "can_send_video_notes", Label: "发送视频留言"},
+	{Key: "voicenotes", Field: "can_send_voice_notes", Label: "发送语音留言"},
+	{Key: "polls", Field: "can_send_polls", Label: "发起投票"},
+	{Key: "other", Field: "can_send_other_messages", Label: "发送贴纸和GIF"},
+	{Key: "previews", Field: "can_add_web_page_previews", Label: "链接预览"},
+	{Key: "info", Field: "can_change_info", Label: "修改群组信息"},
+	{Key: "invite", Field: "can_invite_users", Label: "邀请用户"},
+	{Key: "pin", Field: "can_pin_messages", Label: "置顶消息"},
+	{Key: "topics", Field: "can_manage_topics", Label: "管理话题"},
+}
+
+// mediaPermissionFields 细分的媒体权限字段，修改 media 时一并修改
+var mediaPermissionFields = []string{
+	"can_send_audios", "can_send_documents", "can_send_photos",
+	"can_send_videos", "can_send_video_notes", "can_send_voice_notes",
+}
+
+// findChatPermission 按名称查找群组权限
+func findChatPermission(key string) (chatPermissionInfo, bool) {
+	for _, p := range chatPermissionList {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return chatPermissionInfo{}, false
+}
+
+// permissionValues 将权限转换为 字段名->值 的映射，只包含服务端返回的字段
+func permissionValues(p *ChatPermissions) map[string]bool {
+	values := map[string]bool{}
+	if p == nil {
+		return values
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return values
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		log.Printf("解析群组权限失败: %v", err)
+	}
+	return values
+}
+
+// permissionsFromValues 由 字段名->值 的映射构造权限
+func permissionsFromValues(values map[string]bool) *ChatPermissions {
+	p := &ChatPermissions{}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return p
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		log.Printf("构造群组权限失败: %v", err)
+	}
+	return p
+}
+
+// usesIndependentPermissions 权限中是否包含细分的媒体权限
+func usesIndependentPermissions(p *ChatPermissions) bool {
+	values := permissionValues(p)
+	for _, field := range mediaPermissionFields {
+		if _, ok := values[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyPermissions 返回禁止成员发送任何内容的权限，保留原有的非发言类权限
+func readOnlyPermissions(current *ChatPermissions) *ChatPermissions {
+	values := permissionValues(current)
+	for _, p := range chatPermissionList {
+		if strings.HasPrefix(p.Field, "can_send_") || p.Field == "can_add_web_page_previews" {
+			values[p.Field] = false
+		}
+	}
+	return permissionsFromValues(values)
+}
+
+// setChatPermissions 设置群组默认权限 (服务端返回了细分媒体权限时按细分权限设置)
+func (h *MessageHandler) setChatPermissions(ctx context.Context, chatID int64, permissions *ChatPermissions) error {
+	return h.client.SetChatPermissions(ctx, SetChatPermissionsParams{
+		ChatID:                        chatID,
+		Permissions:                   permissions,
+		UseIndependentChatPermissions: usesIndependentPermissions(permissions),
+	})
+}
+
+// currentChatPermissions 获取群组当前的默认权限
+func (h *MessageHandler) currentChatPermissions(ctx context.Context, chatID int64) (*ChatPermissions, error) {
+	chat, err := h.client.GetChat(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.Permissions == nil {
+		return &ChatPermissions{}, nil
+	}
+	return chat.Permissions, nil
+}
+
+// formatChatPermissions 格式化群组权限
+func formatChatPermissions(p *ChatPermissions) string {
+	values := permissionValues(p)
+
+	var b strings.Builder
+	for _, info := range chatPermissionList {
+		value, ok := values[info.Field]
+		if !ok {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s %s (%s)\n", checkMark(value), info.Label, info.Key))
+	}
+	return b.String()
+}
+
+// handlePermissionsCommand 处理 /permissions 命令，显示群组成员的默认权限
+func (h *MessageHandler) handlePermissionsCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	permissions, err := h.currentChatPermissions(ctx, message.Chat.ID)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 获取群组权限失败")
+	}
+
+	text := "🔑 成员默认权限:\n\n" + formatChatPermissions(permissions)
+	if h.settings.Get(message.Chat.ID).ReadOnly {
+		text += "\n📖 当前处于只读模式"
+	}
+	return h.sendReply(ctx, message, text)
+}
+
+// handleSetPermCommand 处理 /setperm <权限> on|off 命令
+func (h *MessageHandler) handleSetPermCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightRestrictMembers); !ok {
+		return err
+	}
+
+	keys := make([]string, len(chatPermissionList))
+	for i, p := range chatPermissionList {
+		keys[i] = p.Key
+	}
+	usage := "❌ 用法: /setperm <权限> on|off\n可用权限: " + strings.Join(keys, ", ")
+
+	if len(args) < 2 {
+		return h.sendReply(ctx, message, usage)
+	}
+
+	info, ok := findChatPermission(strings.ToLower(args[0]))
+	if !ok {
+		return h.sendReply(ctx, message, usage)
+	}
+
+	var value bool
+	switch strings.ToLower(args[1]) {
+	case "on":
+		value = true
+	case "off":
+		value = false
+	default:
+		return h.sendReply(ctx, message, usage)
+	}
+
+	// 夜间模式和只读模式结束时会恢复各自保存的权限，期间的修改会被覆盖，因此不允许修改
+	h.nightModeMu.Lock()
+	defer h.nightModeMu.Unlock()
+
+	s := h.settings.Get(message.Chat.ID)
+	if s.NightMode != nil && s.NightMode.Active {
+		return h.sendReply(ctx, message, "❌ 夜间模式生效中，请等夜间模式结束或先发送 /nightmode off")
+	}
+	if s.ReadOnly {
+		return h.sendReply(ctx, message, "❌ 只读模式生效中，请先发送 /readonly off")
+	}
+
+	current, err := h.currentChatPermissions(ctx, message.Chat.ID)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 获取群组权限失败")
+	}
+
+	values := permissionValues(current)
+	values[info.Field] = value
+	if info.Key == "media" {
+		for _, field := range mediaPermissionFields {
+			if _, ok := values[field]; ok {
+				values[field] = value
+			}
+		}
+	}
+
+	if err := h.setChatPermissions(ctx, message.Chat.ID, permissionsFromValues(values)); err != nil {
+		return h.sendReply(ctx, message, "❌ 设置群组权限失败: "+err.Error())
+	}
+
+	if value {
+		return h.sendReply(ctx, message, "✅ 已开启成员权限: "+info.Label)
+	}
+	return h.sendReply(ctx, message, "✅ 已关闭成员权限: "+info.Label)
+}
+
+// handleReadOnlyCommand 处理 /readonly on|off 命令
+// 开启时保存当前权限并禁止成员发言，关闭时恢复保存的权限
+func (h *MessageHandler) handleReadOnlyCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightRestrictMembers); !ok {
+		return err
+	}
+
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, "❌ 用法: /readonly on|off")
+	}
+
+	chatID := message.Chat.ID
+
+	// 与夜间模式的定时切换串行执行，两者各自保存的权限不能互相覆盖
+	h.nightModeMu.Lock()
+	defer h.nightModeMu.Unlock()
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		s := h.settings.Get(chatID)
+		if s.ReadOnly {
+			return h.sendReply(ctx, message, "ℹ️ 群组已处于只读模式")
+		}
+		if s.NightMode != nil && s.NightMode.Active {
+			return h.sendReply(ctx, message, "❌ 夜间模式生效中，请等夜间模式结束或先发送 /nightmode off")
+		}
+
+		current, err := h.currentChatPermissions(ctx, chatID)
+		if err != nil {
+			return h.sendReply(ctx, message, "❌ 获取群组权限失败")
+		}
+
+		if err := h.setChatPermissions(ctx, chatID, readOnlyPermissions(current)); err != nil {
+			return h.sendReply(ctx, message, "❌ 设置群组权限失败: "+err.Error())
+		}
+
+		_, err = h.settings.Update(chatID, func(s *ChatSettings) {
+			s.ReadOnly = true
+			s.SavedPermissions = current
+		})
+		if err != nil {
+			log.Printf("保存群组配置失败: %v", err)
+			if err := h.setChatPermissions(ctx, chatID, current); err != nil {
+				log.Printf("回滚只读模式权限失败: %v", err)
+			}
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+
+		return h.sendReply(ctx, message, "📖 已开启只读模式，成员暂时无法发言")
+
+	case "off":
+		s := h.settings.Get(chatID)
+		if !s.ReadOnly || s.SavedPermissions == nil {
+			return h.sendReply(ctx, message, "ℹ️ 群组未处于只读模式")
+		}
+
+		if err := h.setChatPermissions(ctx, chatID, s.SavedPermissions); err != nil {
+			return h.sendReply(ctx, message, "❌ 恢复群组权限失败: "+err.Error())
+		}
+
+		_, err := h.settings.Update(chatID, func(s *ChatSettings) {
+			s.ReadOnly = false
+			s.SavedPermissions = nil
+		})
+		if err != nil {
+			log.Printf("保存群组配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+
+		return h.sendReply(ctx, message, "✅ 已关闭只读模式，成员权限已恢复")
+	}
+
+	return h.sendReply(ctx, message, "❌ 用法: /readonly on|off")
+}
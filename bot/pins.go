@@ -0,0 +1,214 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// unpinAllCallbackPrefix /unpinall 确认按钮的 callback_data 前缀
+const unpinAllCallbackPrefix = "unpinall:"
+
+// handlePinCommand 处理 /pin 命令，置顶回复的消息
+// 默认静默置顶，/pin loud 时通知所有成员
+func (h *MessageHandler) handlePinCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightPinMessages); !ok {
+		return err
+	}
+
+	if message.ReplyToMessage == nil {
+		return h.sendReply(ctx, message, "❌ 请回复要置顶的消息\n用法: /pin [loud]")
+	}
+
+	loud := len(args) > 0 && (strings.ToLower(args[0]) == "loud" || strings.ToLower(args[0]) == "notify")
+
+	err := h.client.PinChatMessage(ctx, PinChatMessageParams{
+		ChatID:              message.Chat.ID,
+		MessageID:           message.ReplyToMessage.MessageID,
+		DisableNotification: !loud,
+	})
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 置顶失败: "+err.Error())
+	}
+
+	if err := h.client.DeleteMessage(ctx, message.Chat.ID, message.MessageID); err != nil {
+		log.Printf("删除命令消息失败: %v", err)
+	}
+	return nil
+}
+
+// handleUnpinCommand 处理 /unpin 命令，取消置顶回复的消息，未回复时取消最近一条置顶
+func (h *MessageHandler) handleUnpinCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightPinMessages); !ok {
+		return err
+	}
+
+	messageID := 0
+	if message.ReplyToMessage != nil {
+		messageID = message.ReplyToMessage.MessageID
+	}
+
+	if err := h.client.UnpinChatMessage(ctx, message.Chat.ID, messageID); err != nil {
+		return h.sendReply(ctx, message, "❌ 取消置顶失败: "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, "✅ 已取消置顶")
+}
+
+// handleUnpinAllCommand 处理 /unpinall 命令，确认后取消所有置顶
+func (h *MessageHandler) handleUnpinAllCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightPinMessages); !ok {
+		return err
+	}
+
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID:           message.Chat.ID,
+		Text:             "⚠️ 确定要取消本群的所有置顶消息吗？",
+		ReplyToMessageID: message.MessageID,
+		ReplyMarkup: &InlineKeyboardMarkup{
+			InlineKeyboard: [][]InlineKeyboardButton{{
+				{Text: "✅ 确认", CallbackData: unpinAllCallbackPrefix + "confirm"},
+				{Text: "❌ 取消", CallbackData: unpinAllCallbackPrefix + "cancel"},
+			}},
+		},
+	})
+	return err
+}
+
+// handleUnpinAllCallback 处理 /unpinall 的确认按钮
+func (h *MessageHandler) handleUnpinAllCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return h.answerCallback(ctx, query, "", false)
+	}
+
+	chatID := query.Message.Chat.ID
+	if !h.memberHasRight(ctx, chatID, query.From.ID, RightPinMessages) {
+		return h.answerCallback(ctx, query, fmt.Sprintf("❌ 您没有「%s」权限", adminRightLabels[RightPinMessages]), true)
+	}
+
+	text := "已取消操作"
+	if data == "confirm" {
+		if err := h.client.UnpinAllChatMessages(ctx, chatID); err != nil {
+			return h.answerCallback(ctx, query, "❌ 取消置顶失败: "+err.Error(), true)
+		}
+		text = fmt.Sprintf("✅ %s 已取消所有置顶消息", getUserName(query.From))
+	}
+
+	err := h.client.EditMessageText(ctx, EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: query.Message.MessageID,
+		Text:      text,
+	})
+	if err != nil {
+		log.Printf("更新确认消息失败: %v", err)
+	}
+
+	return h.answerCallback(ctx, query, "", false)
+}
+
+// handlePinnedCommand 处理 /pinned 命令，显示当前置顶消息
+func (h *MessageHandler) handlePinnedCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	chat, err := h.client.GetChat(ctx, message.Chat.ID)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 获取群组信息失败")
+	}
+
+	if chat.PinnedMessage == nil {
+		return h.sendReply(ctx, message, "📭 当前没有置顶消息")
+	}
+
+	pinned := chat.PinnedMessage
+	text := "📌 当前置顶消息"
+	if link := messageLink(chat, pinned.MessageID); link != "" {
+		text += ": " + link
+	}
+	if preview := messageText(pinned); preview != "" {
+		runes := []rune(preview)
+		if len(runes) > 100 {
+			preview = string(runes[:100]) + "..."
+		}
+		text += "\n\n" + preview
+	}
+
+	_, err = h.client.SendMessage(ctx, SendMessageParams{
+		ChatID:                message.Chat.ID,
+		Text:                  text,
+		ReplyToMessageID:      pinned.MessageID,
+		DisableWebPagePreview: true,
+	})
+	return err
+}
+
+// handleAntiChannelPinCommand 处理 /antichannelpin 命令
+// 开启后自动取消关联频道转发到群组时产生的置顶
+func (h *MessageHandler) handleAntiChannelPinCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if len(args) == 0 {
+		if !h.isSenderAdmin(ctx, message) {
+			return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+		}
+		s := h.settings.Get(message.Chat.ID)
+		return h.sendReply(ctx, message, fmt.Sprintf("📌 防频道置顶: %s\n用法: /antichannelpin on|off", onOff(s.AntiChannelPin)))
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightPinMessages); !ok {
+		return err
+	}
+
+	var enabled bool
+	switch strings.ToLower(args[0]) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return h.sendReply(ctx, message, "❌ 用法: /antichannelpin on|off")
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		s.AntiChannelPin = enabled
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	if enabled {
+		return h.sendReply(ctx, message, "✅ 已开启防频道置顶")
+	}
+	return h.sendReply(ctx, message, "✅ 已关闭防频道置顶")
+}
+
+// checkChannelPin 关联频道的帖子自动转发到群组时会被自动置顶，开启防频道置顶后取消该置顶
+func (h *MessageHandler) checkChannelPin(ctx context.Context, message *Message) {
+	if !message.IsAutomaticForward {
+		return
+	}
+	if !h.settings.Get(message.Chat.ID).AntiChannelPin {
+		return
+	}
+
+	if err := h.client.UnpinChatMessage(ctx, message.Chat.ID, message.MessageID); err != nil {
+		log.Printf("取消频道消息置顶失败: %v", err)
+	}
+}
@@ -0,0 +1,575 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// promoteCallbackPrefix /promote 权限编辑按钮的 callback_data 前缀
+const promoteCallbackPrefix = "promote:"
+
+// promoteEditorTTL 权限编辑按钮的有效期
+const promoteEditorTTL = 5 * time.Minute
+
+// promotableRight 可以通过 /promote 授予的管理员权限
+type promotableRight struct {
+	Key   string // 命令参数和按钮中使用的简称
+	Right AdminRight
+}
+
+// promotableRights 群组中可授予的管理员权限 (发布/编辑消息仅适用于频道，不在此列)
+var promotableRights = []promotableRight{
+	{"manage", RightManageChat},
+	{"delete", RightDeleteMessages},
+	{"restrict", RightRestrictMembers},
+	{"invite", RightInviteUsers},
+	{"pin", RightPinMessages},
+	{"info", RightChangeInfo},
+	{"video", RightManageVideoChat},
+	{"topics", RightManageTopics},
+	{"promote", RightPromoteMembers},
+}
+
+// promotePresetMod 默认的 mod 预设，与之前 /promote 固定授予的权限一致
+var promotePresetMod = []AdminRight{
+	RightDeleteMessages,
+	RightRestrictMembers,
+	RightInviteUsers,
+	RightPinMessages,
+}
+
+// findPromotableRight 按简称查找可授予的权限
+func findPromotableRight(key string) (promotableRight, bool) {
+	for _, r := range promotableRights {
+		if r.Key == key {
+			return r, true
+		}
+	}
+	return promotableRight{}, false
+}
+
+// promotableRightKeys 返回所有权限简称，用于提示
+func promotableRightKeys() string {
+	keys := make([]string, 0, len(promotableRights))
+	for _, r := range promotableRights {
+		keys = append(keys, r.Key)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// promoteParams 根据选中的权限构造 promoteChatMember 参数
+func promoteParams(chatID, userID int64, rights map[AdminRight]bool) PromoteChatMemberParams {
+	return PromoteChatMemberParams{
+		ChatID:              chatID,
+		UserID:              userID,
+		CanManageChat:       rights[RightManageChat],
+		CanDeleteMessages:   rights[RightDeleteMessages],
+		CanManageVideoChats: rights[RightManageVideoChat],
+		CanRestrictMembers:  rights[RightRestrictMembers],
+		CanPromoteMembers:   rights[RightPromoteMembers],
+		CanChangeInfo:       rights[RightChangeInfo],
+		CanInviteUsers:      rights[RightInviteUsers],
+		CanPinMessages:      rights[RightPinMessages],
+		CanManageTopics:     rights[RightManageTopics],
+	}
+}
+
+// formatRightList 按固定顺序列出选中的权限名称
+func formatRightList(rights map[AdminRight]bool) string {
+	var labels []string
+	for _, r := range promotableRights {
+		if rights[r.Right] {
+			labels = append(labels, adminRightLabels[r.Right])
+		}
+	}
+	if len(labels) == 0 {
+		return "无"
+	}
+	return strings.Join(labels, ", ")
+}
+
+// pendingPromotion 等待确认的提升管理员操作
+type pendingPromotion struct {
+	ChatID     int64
+	Target     *User // 可能为空，仅知道用户ID时只显示ID
+	TargetID   int64
+	CallerID   int64
+	Existing   bool // 目标已是管理员，确认后修改其权限
+	Grantable  map[AdminRight]bool
+	Selected   map[AdminRight]bool
+	ExpiresAt  time.Time
+	SourceChat *Chat
+}
+
+// clone 复制待确认的操作，避免在锁外读写共享的权限集合
+func (p *pendingPromotion) clone() *pendingPromotion {
+	c := *p
+	c.Selected = make(map[AdminRight]bool, len(p.Selected))
+	for r, on := range p.Selected {
+		c.Selected[r] = on
+	}
+	return &c
+}
+
+// promoteRequests 保存正在编辑权限的 /promote 操作
+type promoteRequests struct {
+	mu      sync.Mutex
+	pending map[string]*pendingPromotion
+}
+
+// newPromoteRequests 创建权限编辑队列
+func newPromoteRequests() *promoteRequests {
+	return &promoteRequests{pending: make(map[string]*pendingPromotion)}
+}
+
+// add 保存待确认的操作并返回其ID
+func (r *promoteRequests) add(p *pendingPromotion) (string, error) {
+	id, err := randomToken(6)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, pending := range r.pending {
+		if now.After(pending.ExpiresAt) {
+			delete(r.pending, k)
+		}
+	}
+
+	r.pending[id] = p
+	return id, nil
+}
+
+// get 返回待确认操作的副本
+func (r *promoteRequests) get(id string) (*pendingPromotion, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pending[id]
+	if !ok || time.Now().After(p.ExpiresAt) {
+		return nil, false
+	}
+	return p.clone(), true
+}
+
+// toggle 切换一项权限的选中状态，返回切换后的副本
+func (r *promoteRequests) toggle(id string, right AdminRight) (*pendingPromotion, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pending[id]
+	if !ok || time.Now().After(p.ExpiresAt) {
+		return nil, false
+	}
+	p.Selected[right] = !p.Selected[right]
+	return p.clone(), true
+}
+
+// take 取出并删除待确认的操作
+func (r *promoteRequests) take(id string) (*pendingPromotion, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pending[id]
+	delete(r.pending, id)
+	if !ok || time.Now().After(p.ExpiresAt) {
+		return nil, false
+	}
+	return p, true
+}
+
+// grantableRights 返回调用者可以授予的权限: 调用者和Bot都必须拥有该权限
+func (h *MessageHandler) grantableRights(ctx context.Context, chatID, callerID int64) map[AdminRight]bool {
+	grantable := make(map[AdminRight]bool)
+
+	caller, ok := h.adminMember(ctx, chatID, callerID)
+	if !ok || h.botUser == nil {
+		return grantable
+	}
+	bot, ok := h.adminMember(ctx, chatID, h.botUser.ID)
+	if !ok {
+		return grantable
+	}
+
+	for _, r := range promotableRights {
+		if caller.HasRight(r.Right) && bot.HasRight(r.Right) {
+			grantable[r.Right] = true
+		}
+	}
+	return grantable
+}
+
+// checkAdminEditable 检查调用者能否修改已是管理员的目标用户的权限
+// 群主、非Bot提升的管理员、以及拥有 grantable 以外权限的管理员都不能修改，否则可借此降低更高级管理员的权限
+// 返回目标的成员信息、目标是否为管理员，以及不能修改时的原因
+func (h *MessageHandler) checkAdminEditable(ctx context.Context, chatID, userID int64, grantable map[AdminRight]bool) (ChatMember, bool, string) {
+	member, ok := h.adminMember(ctx, chatID, userID)
+	if !ok {
+		return ChatMember{}, false, ""
+	}
+	if member.Status == MemberStatusCreator {
+		return member, true, "❌ 无法修改群主的权限"
+	}
+	if !member.CanBeEdited {
+		return member, true, "❌ 只能修改由Bot提升的管理员的权限"
+	}
+	for _, r := range promotableRights {
+		if member.HasRight(r.Right) && !grantable[r.Right] {
+			return member, true, fmt.Sprintf("❌ 该管理员拥有您或Bot没有的「%s」权限，无法修改", adminRightLabels[r.Right])
+		}
+	}
+	return member, true, ""
+}
+
+// parseTargetUser 解析命令的目标用户: 第一个参数为用户ID，或回复目标用户的消息
+// 返回目标用户ID、已知的用户信息 (可能为空) 和剩余参数
+func parseTargetUser(message *Message, args []string) (int64, *User, []string, bool) {
+	if len(args) > 0 {
+		if id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "@"), 10, 64); err == nil {
+			return id, nil, args[1:], true
+		}
+	}
+	if message.ReplyToMessage != nil && message.ReplyToMessage.From != nil {
+		return message.ReplyToMessage.From.ID, message.ReplyToMessage.From, args, true
+	}
+	return 0, nil, args, false
+}
+
+// handlePromoteCommand 处理 /promote 命令
+// 用法: /promote <用户ID> [mod|full|custom|权限...]，或回复用户的消息发送 /promote [预设|权限...]
+// 命令不会立即生效，而是先显示权限勾选按钮，确认后再提升
+func (h *MessageHandler) handlePromoteCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	// 检查用户和Bot的添加管理员权限
+	if ok, err := h.requireRight(ctx, message, RightPromoteMembers); !ok {
+		return err
+	}
+
+	usage := "❌ 用法: /promote <用户ID> [mod|full|custom|权限...]，或回复用户的消息发送 /promote [预设|权限...]\n可用权限: " + promotableRightKeys()
+
+	userID, target, rest, ok := parseTargetUser(message, args)
+	if !ok {
+		return h.sendReply(ctx, message, usage)
+	}
+	if h.botUser != nil && userID == h.botUser.ID {
+		return h.sendReply(ctx, message, "❌ 不能修改Bot自身的权限")
+	}
+
+	grantable := h.grantableRights(ctx, message.Chat.ID, message.From.ID)
+
+	member, existing, problem := h.checkAdminEditable(ctx, message.Chat.ID, userID, grantable)
+	if problem != "" {
+		return h.sendReply(ctx, message, problem)
+	}
+
+	// 目标已是管理员时从其现有权限开始编辑，预设和指定的权限在此基础上添加
+	selected := make(map[AdminRight]bool)
+	preset := "mod"
+	if existing {
+		for _, r := range promotableRights {
+			if member.HasRight(r.Right) {
+				selected[r.Right] = true
+			}
+		}
+		preset = "custom"
+		if target == nil {
+			target = member.User
+		}
+	}
+	if len(rest) > 0 {
+		preset = strings.ToLower(rest[0])
+	}
+
+	// 预设中调用者无法授予的权限直接去掉，明确指定的权限则提示错误
+	switch preset {
+	case "mod":
+		for _, r := range promotePresetMod {
+			if grantable[r] {
+				selected[r] = true
+			}
+		}
+	case "full":
+		for r := range grantable {
+			selected[r] = true
+		}
+	case "custom":
+	default:
+		for _, arg := range rest {
+			r, ok := findPromotableRight(strings.ToLower(arg))
+			if !ok {
+				return h.sendReply(ctx, message, "❌ 未知的权限: "+arg+"\n可用权限: "+promotableRightKeys())
+			}
+			if !grantable[r.Right] {
+				return h.sendReply(ctx, message, fmt.Sprintf("❌ 您或Bot没有「%s」权限，无法授予", adminRightLabels[r.Right]))
+			}
+			selected[r.Right] = true
+		}
+	}
+
+	p := &pendingPromotion{
+		ChatID:     message.Chat.ID,
+		Target:     target,
+		TargetID:   userID,
+		CallerID:   message.From.ID,
+		Existing:   existing,
+		Grantable:  grantable,
+		Selected:   selected,
+		ExpiresAt:  time.Now().Add(promoteEditorTTL),
+		SourceChat: message.Chat,
+	}
+
+	id, err := h.promotions.add(p)
+	if err != nil {
+		return fmt.Errorf("生成权限编辑请求失败: %w", err)
+	}
+
+	_, err = h.client.SendMessage(ctx, SendMessageParams{
+		ChatID:           message.Chat.ID,
+		Text:             promoteEditorText(p),
+		ReplyToMessageID: message.MessageID,
+		ReplyMarkup:      promoteEditorKeyboard(id, p),
+	})
+	return err
+}
+
+// promoteTargetName 返回目标用户的显示名称
+func promoteTargetName(p *pendingPromotion) string {
+	if p.Target != nil {
+		return formatUser(p.Target)
+	}
+	return strconv.FormatInt(p.TargetID, 10)
+}
+
+// promoteEditorText 生成权限编辑消息的文本
+func promoteEditorText(p *pendingPromotion) string {
+	title := fmt.Sprintf("👮 提升 %s 为管理员", promoteTargetName(p))
+	if p.Existing {
+		title = fmt.Sprintf("👮 修改 %s 的管理员权限", promoteTargetName(p))
+	}
+	return fmt.Sprintf("%s\n已选择: %s\n\n点击按钮切换权限，🔒 表示您或Bot没有该权限，无法授予",
+		title, formatRightList(p.Selected))
+}
+
+// promoteEditorKeyboard 生成权限勾选按钮，每行两项
+func promoteEditorKeyboard(id string, p *pendingPromotion) *InlineKeyboardMarkup {
+	var rows [][]InlineKeyboardButton
+	var row []InlineKeyboardButton
+	for _, r := range promotableRights {
+		mark := "⬜"
+		switch {
+		case !p.Grantable[r.Right]:
+			mark = "🔒"
+		case p.Selected[r.Right]:
+			mark = "✅"
+		}
+		row = append(row, InlineKeyboardButton{
+			Text:         mark + " " + adminRightLabels[r.Right],
+			CallbackData: promoteCallbackPrefix + id + ":" + r.Key,
+		})
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	rows = append(rows, []InlineKeyboardButton{
+		{Text: "✅ 确认提升", CallbackData: promoteCallbackPrefix + id + ":ok"},
+		{Text: "❌ 取消", CallbackData: promoteCallbackPrefix + id + ":cancel"},
+	})
+	return &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// handlePromoteCallback 处理权限编辑按钮，data 格式为 <ID>:<权限简称|ok|cancel>
+func (h *MessageHandler) handlePromoteCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return h.answerCallback(ctx, query, "", false)
+	}
+
+	id, action, _ := strings.Cut(data, ":")
+	p, ok := h.promotions.get(id)
+	if !ok {
+		return h.answerCallback(ctx, query, "❌ 操作已过期，请重新发送 /promote", true)
+	}
+	if query.From.ID != p.CallerID {
+		return h.answerCallback(ctx, query, "❌ 只有发起者可以修改", true)
+	}
+
+	switch action {
+	case "cancel":
+		h.promotions.take(id)
+		h.finishPromoteEditor(ctx, query, "已取消提升管理员")
+		return h.answerCallback(ctx, query, "", false)
+
+	case "ok":
+		return h.confirmPromotion(ctx, query, id)
+	}
+
+	r, ok := findPromotableRight(action)
+	if !ok {
+		return h.answerCallback(ctx, query, "", false)
+	}
+	if !p.Grantable[r.Right] {
+		return h.answerCallback(ctx, query, fmt.Sprintf("🔒 您或Bot没有「%s」权限，无法授予", adminRightLabels[r.Right]), true)
+	}
+
+	p, ok = h.promotions.toggle(id, r.Right)
+	if !ok {
+		return h.answerCallback(ctx, query, "❌ 操作已过期，请重新发送 /promote", true)
+	}
+
+	err := h.client.EditMessageText(ctx, EditMessageTextParams{
+		ChatID:      query.Message.Chat.ID,
+		MessageID:   query.Message.MessageID,
+		Text:        promoteEditorText(p),
+		ReplyMarkup: promoteEditorKeyboard(id, p),
+	})
+	if err != nil {
+		log.Printf("更新权限编辑消息失败: %v", err)
+	}
+	return h.answerCallback(ctx, query, "", false)
+}
+
+// confirmPromotion 按选中的权限提升管理员
+func (h *MessageHandler) confirmPromotion(ctx context.Context, query *CallbackQuery, id string) error {
+	p, ok := h.promotions.get(id)
+	if !ok {
+		return h.answerCallback(ctx, query, "❌ 操作已过期，请重新发送 /promote", true)
+	}
+
+	selected := 0
+	for _, on := range p.Selected {
+		if on {
+			selected++
+		}
+	}
+	if selected == 0 {
+		return h.answerCallback(ctx, query, "❌ 请至少选择一项权限", true)
+	}
+
+	// 编辑期间调用者的权限可能已变化，确认时重新检查
+	if !h.memberHasRight(ctx, p.ChatID, query.From.ID, RightPromoteMembers) {
+		return h.answerCallback(ctx, query, fmt.Sprintf("❌ 您没有「%s」权限", adminRightLabels[RightPromoteMembers]), true)
+	}
+	grantable := h.grantableRights(ctx, p.ChatID, query.From.ID)
+	for r, on := range p.Selected {
+		if on && !grantable[r] {
+			return h.answerCallback(ctx, query, fmt.Sprintf("❌ 您或Bot没有「%s」权限，无法授予", adminRightLabels[r]), true)
+		}
+	}
+	// 目标的权限同样可能在编辑期间被他人修改
+	if _, _, problem := h.checkAdminEditable(ctx, p.ChatID, p.TargetID, grantable); problem != "" {
+		return h.answerCallback(ctx, query, problem, true)
+	}
+
+	if _, ok := h.promotions.take(id); !ok {
+		return h.answerCallback(ctx, query, "❌ 操作已过期，请重新发送 /promote", true)
+	}
+
+	if err := h.client.PromoteChatMember(ctx, promoteParams(p.ChatID, p.TargetID, p.Selected)); err != nil {
+		return h.answerCallback(ctx, query, "❌ 提升管理员失败: "+err.Error(), true)
+	}
+	h.admins.invalidate(p.ChatID)
+
+	h.logModAction(ctx, ModAction{
+		Action:   ModActionPromote,
+		Chat:     p.SourceChat,
+		Actor:    query.From,
+		TargetID: p.TargetID,
+		Target:   p.Target,
+		Reason:   "权限: " + formatRightList(p.Selected),
+		Message:  query.Message,
+	})
+
+	result := fmt.Sprintf("✅ %s 已被提升为管理员", promoteTargetName(p))
+	if p.Existing {
+		result = fmt.Sprintf("✅ 已修改 %s 的管理员权限", promoteTargetName(p))
+	}
+	h.finishPromoteEditor(ctx, query, result+"\n权限: "+formatRightList(p.Selected))
+	return h.answerCallback(ctx, query, "", false)
+}
+
+// finishPromoteEditor 将权限编辑消息替换为结果并移除按钮
+func (h *MessageHandler) finishPromoteEditor(ctx context.Context, query *CallbackQuery, text string) {
+	err := h.client.EditMessageText(ctx, EditMessageTextParams{
+		ChatID:    query.Message.Chat.ID,
+		MessageID: query.Message.MessageID,
+		Text:      text,
+	})
+	if err != nil {
+		log.Printf("更新权限编辑消息失败: %v", err)
+	}
+}
+
+// handleDemoteCommand 处理 /demote <用户ID> 命令，也可以回复管理员的消息发送 /demote
+func (h *MessageHandler) handleDemoteCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightPromoteMembers); !ok {
+		return err
+	}
+
+	userID, target, _, ok := parseTargetUser(message, args)
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 用法: /demote <用户ID>，或回复管理员的消息发送 /demote")
+	}
+
+	member, ok := h.adminMember(ctx, message.Chat.ID, userID)
+	if !ok {
+		return h.sendReply(ctx, message, "❌ 该用户不是管理员")
+	}
+	if member.Status == MemberStatusCreator {
+		return h.sendReply(ctx, message, "❌ 无法撤销群主")
+	}
+	if !member.CanBeEdited {
+		return h.sendReply(ctx, message, "❌ 只能撤销由Bot提升的管理员")
+	}
+
+	// 不能撤销拥有自己所没有的权限的管理员
+	if caller, ok := h.adminMember(ctx, message.Chat.ID, message.From.ID); ok {
+		for _, r := range promotableRights {
+			if member.HasRight(r.Right) && !caller.HasRight(r.Right) {
+				return h.sendReply(ctx, message, fmt.Sprintf("❌ 该管理员拥有您没有的「%s」权限，无法撤销", adminRightLabels[r.Right]))
+			}
+		}
+	}
+
+	// 所有权限均为 false 即撤销管理员
+	err := h.client.PromoteChatMember(ctx, PromoteChatMemberParams{
+		ChatID: message.Chat.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 撤销管理员失败: "+err.Error())
+	}
+	h.admins.invalidate(message.Chat.ID)
+
+	if target == nil {
+		target = member.User
+	}
+	h.logModAction(ctx, ModAction{
+		Action:   ModActionDemote,
+		Chat:     message.Chat,
+		Actor:    message.From,
+		TargetID: userID,
+		Target:   target,
+		Message:  message,
+	})
+
+	return h.sendReply(ctx, message, "✅ 已撤销 "+formatUser(target)+" 的管理员身份")
+}
@@ -0,0 +1,204 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// recentMessagesPerChat 每个聊天记录的最近消息数
+const recentMessagesPerChat = 1000
+
+// maxPurgeMessages /purge 单次最多删除的消息数
+const maxPurgeMessages = 1000
+
+// recentMessage 最近消息记录
+type recentMessage struct {
+	MessageID int
+	UserID    int64
+}
+
+// recentMessages 单个聊天的最近消息 (环形缓冲)
+type recentMessages struct {
+	items []recentMessage
+	next  int
+}
+
+// messageTracker 记录各聊天最近的消息ID
+// Bot无法拉取聊天历史，按用户清理消息时只能依赖这里的记录
+type messageTracker struct {
+	mu    sync.Mutex
+	chats map[int64]*recentMessages
+}
+
+// newMessageTracker 创建消息记录器
+func newMessageTracker() *messageTracker {
+	return &messageTracker{chats: make(map[int64]*recentMessages)}
+}
+
+// record 记录一条消息
+func (t *messageTracker) record(chatID int64, messageID int, userID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	recent, ok := t.chats[chatID]
+	if !ok {
+		recent = &recentMessages{}
+		t.chats[chatID] = recent
+	}
+
+	item := recentMessage{MessageID: messageID, UserID: userID}
+	if len(recent.items) < recentMessagesPerChat {
+		recent.items = append(recent.items, item)
+		return
+	}
+	recent.items[recent.next] = item
+	recent.next = (recent.next + 1) % recentMessagesPerChat
+}
+
+// takeByUser 取出用户最近的 n 条消息ID，并从记录中移除
+func (t *messageTracker) takeByUser(chatID, userID int64, n int) []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	recent, ok := t.chats[chatID]
+	if !ok {
+		return nil
+	}
+
+	// 按从新到旧的顺序遍历环形缓冲
+	var ids []int
+	size := len(recent.items)
+	for i := 0; i < size && len(ids) < n; i++ {
+		idx := (recent.next - 1 - i + size) % size
+		item := &recent.items[idx]
+		if item.UserID == userID && item.MessageID != 0 {
+			ids = append(ids, item.MessageID)
+			item.MessageID = 0
+		}
+	}
+	return ids
+}
+
+// trackMessage 记录群组中的消息，供按用户清理时使用
+func (h *MessageHandler) trackMessage(message *Message) {
+	if message.Chat.Type == "private" || message.From == nil {
+		return
+	}
+	h.recent.record(message.Chat.ID, message.MessageID, message.From.ID)
+}
+
+// handlePurgeCommand 处理 /purge 命令，删除从回复的消息到命令之间的所有消息
+func (h *MessageHandler) handlePurgeCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightDeleteMessages); !ok {
+		return err
+	}
+
+	if message.ReplyToMessage == nil {
+		return h.sendReply(ctx, message, "❌ 请回复要开始清理的消息")
+	}
+
+	// 群组内的消息ID连续递增，可以直接按区间删除 (包括Bot自己发送的消息)
+	from := message.ReplyToMessage.MessageID
+	if message.MessageID-from+1 > maxPurgeMessages {
+		from = message.MessageID - maxPurgeMessages + 1
+	}
+
+	ids := make([]int, 0, message.MessageID-from+1)
+	for id := from; id <= message.MessageID; id++ {
+		ids = append(ids, id)
+	}
+
+	if err := h.client.DeleteMessages(ctx, message.Chat.ID, ids); err != nil {
+		return h.sendReply(ctx, message, "❌ 清理消息失败: "+err.Error())
+	}
+
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: message.Chat.ID,
+		Text:   fmt.Sprintf("🧹 已尝试清理 %d 条消息 (不存在或已删除的消息会被跳过)", len(ids)),
+	})
+	return err
+}
+
+// handleDelCommand 处理 /del 命令，删除回复的消息和命令本身
+func (h *MessageHandler) handleDelCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightDeleteMessages); !ok {
+		return err
+	}
+
+	if message.ReplyToMessage == nil {
+		return h.sendReply(ctx, message, "❌ 请回复要删除的消息")
+	}
+
+	ids := []int{message.ReplyToMessage.MessageID, message.MessageID}
+	if err := h.client.DeleteMessages(ctx, message.Chat.ID, ids); err != nil {
+		return h.sendReply(ctx, message, "❌ 删除消息失败: "+err.Error())
+	}
+	return nil
+}
+
+// handlePurgeUserCommand 处理 /purgeuser 命令，删除指定用户最近的 n 条消息
+// 用法: /purgeuser <用户ID> <条数>，或回复用户的消息 /purgeuser <条数>
+func (h *MessageHandler) handlePurgeUserCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightDeleteMessages); !ok {
+		return err
+	}
+
+	usage := "❌ 用法: /purgeuser <用户ID> <条数>，或回复用户的消息发送 /purgeuser <条数>"
+
+	var userID int64
+	if message.ReplyToMessage != nil && message.ReplyToMessage.From != nil && len(args) == 1 {
+		userID = message.ReplyToMessage.From.ID
+	} else if len(args) == 2 {
+		id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "@"), 10, 64)
+		if err != nil {
+			return h.sendReply(ctx, message, "❌ 无效的用户ID")
+		}
+		userID = id
+		args = args[1:]
+	} else {
+		return h.sendReply(ctx, message, usage)
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return h.sendReply(ctx, message, usage)
+	}
+	if n > recentMessagesPerChat {
+		n = recentMessagesPerChat
+	}
+
+	ids := h.recent.takeByUser(message.Chat.ID, userID, n)
+	if len(ids) == 0 {
+		return h.sendReply(ctx, message, "📭 没有找到该用户最近的消息记录")
+	}
+
+	if err := h.client.DeleteMessages(ctx, message.Chat.ID, ids); err != nil {
+		return h.sendReply(ctx, message, "❌ 清理消息失败: "+err.Error())
+	}
+
+	if err := h.client.DeleteMessage(ctx, message.Chat.ID, message.MessageID); err != nil {
+		log.Printf("删除命令消息失败: %v", err)
+	}
+
+	_, err = h.client.SendMessage(ctx, SendMessageParams{
+		ChatID: message.Chat.ID,
+		Text:   fmt.Sprintf("🧹 已清理用户 %d 的 %d 条消息", userID, len(ids)),
+	})
+	return err
+}
@@ -0,0 +1,538 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reportCallbackPrefix 举报通知中处理按钮的 callback_data 前缀
+const reportCallbackPrefix = "report:"
+
+// 举报相关的时间限制
+const (
+	reportCooldown     = 2 * time.Minute  // 同一成员两次举报的最小间隔
+	reportTTL          = 24 * time.Hour   // 举报记录的保留时间，过期后按钮失效
+	reportMuteDuration = time.Hour        // 通过举报按钮禁言的时长
+	reportPruneEvery   = 10 * time.Minute // 清理过期举报的间隔
+)
+
+// 举报的处理动作
+const (
+	ReportActionDelete  = "del"
+	ReportActionWarn    = "warn"
+	ReportActionMute    = "mute"
+	ReportActionBan     = "ban"
+	ReportActionDismiss = "dismiss"
+)
+
+// reportActionRights 各处理动作所需的管理员权限，忽略举报只需要是管理员
+var reportActionRights = map[string]AdminRight{
+	ReportActionDelete: RightDeleteMessages,
+	ReportActionWarn:   RightRestrictMembers,
+	ReportActionMute:   RightRestrictMembers,
+	ReportActionBan:    RightRestrictMembers,
+}
+
+// reportNotice 发送给管理员或日志频道的一条举报通知
+type reportNotice struct {
+	ChatID    int64
+	MessageID int
+}
+
+// report 一条成员举报
+type report struct {
+	ID        string
+	Chat      *Chat
+	MessageID int // 被举报的消息
+	Reporter  *User
+	Target    *User
+	Reason    string
+	CreatedAt time.Time
+	Notices   []reportNotice
+	Handled   bool
+}
+
+// reportTracker 记录进行中的举报，用于去重、冷却和处理按钮
+type reportTracker struct {
+	mu        sync.Mutex
+	reports   map[string]*report
+	byMessage map[int64]map[int]string      // 群组 -> 被举报消息 -> 举报ID
+	lastSent  map[int64]map[int64]time.Time // 群组 -> 举报人 -> 上次举报时间
+}
+
+// newReportTracker 创建举报记录器
+func newReportTracker() *reportTracker {
+	return &reportTracker{
+		reports:   make(map[string]*report),
+		byMessage: make(map[int64]map[int]string),
+		lastSent:  make(map[int64]map[int64]time.Time),
+	}
+}
+
+// open 登记一条新举报
+// 同一条消息已有未处理的举报时返回 duplicate，举报人仍在冷却中时返回剩余等待时间
+func (t *reportTracker) open(r *report) (duplicate bool, wait time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	chatID := r.Chat.ID
+	if id, ok := t.byMessage[chatID][r.MessageID]; ok {
+		if existing, ok := t.reports[id]; ok && !existing.Handled {
+			return true, 0, nil
+		}
+	}
+
+	if last, ok := t.lastSent[chatID][r.Reporter.ID]; ok {
+		if elapsed := time.Since(last); elapsed < reportCooldown {
+			return false, reportCooldown - elapsed, nil
+		}
+	}
+
+	id, err := randomToken(4)
+	if err != nil {
+		return false, 0, err
+	}
+	r.ID = id
+	t.reports[id] = r
+
+	if t.byMessage[chatID] == nil {
+		t.byMessage[chatID] = make(map[int]string)
+	}
+	t.byMessage[chatID][r.MessageID] = id
+
+	if t.lastSent[chatID] == nil {
+		t.lastSent[chatID] = make(map[int64]time.Time)
+	}
+	t.lastSent[chatID][r.Reporter.ID] = r.CreatedAt
+	return false, 0, nil
+}
+
+// setNotices 记录已发出的通知，处理后需要逐一更新
+func (t *reportTracker) setNotices(id string, notices []reportNotice) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if r, ok := t.reports[id]; ok {
+		r.Notices = notices
+	}
+}
+
+// get 获取举报的副本
+func (t *reportTracker) get(id string) (report, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.reports[id]
+	if !ok {
+		return report{}, false
+	}
+	c := *r
+	c.Notices = append([]reportNotice(nil), r.Notices...)
+	return c, true
+}
+
+// claim 将举报标记为已处理，已被其他管理员处理时返回 false
+func (t *reportTracker) claim(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.reports[id]
+	if !ok || r.Handled {
+		return false
+	}
+	r.Handled = true
+	return true
+}
+
+// release 处理失败时撤销 claim，允许重新处理
+func (t *reportTracker) release(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if r, ok := t.reports[id]; ok {
+		r.Handled = false
+	}
+}
+
+// prune 清理过期的举报和冷却记录
+func (t *reportTracker) prune(ctx context.Context) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, r := range t.reports {
+		if now.Sub(r.CreatedAt) < reportTTL {
+			continue
+		}
+		delete(t.reports, id)
+		if t.byMessage[r.Chat.ID][r.MessageID] == id {
+			delete(t.byMessage[r.Chat.ID], r.MessageID)
+		}
+		if len(t.byMessage[r.Chat.ID]) == 0 {
+			delete(t.byMessage, r.Chat.ID)
+		}
+	}
+
+	for chatID, users := range t.lastSent {
+		for userID, last := range users {
+			if now.Sub(last) >= reportCooldown {
+				delete(users, userID)
+			}
+		}
+		if len(users) == 0 {
+			delete(t.lastSent, chatID)
+		}
+	}
+}
+
+// isAdminMention 消息是否包含 @admin 或 @admins
+func isAdminMention(message *Message) bool {
+	for _, word := range strings.Fields(strings.ToLower(messageText(message))) {
+		if word == "@admin" || word == "@admins" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAdminMention 成员回复消息并 @admin 时视为举报
+func (h *MessageHandler) checkAdminMention(ctx context.Context, message *Message) {
+	if message.Chat.Type == "private" || message.ReplyToMessage == nil || !isAdminMention(message) {
+		return
+	}
+	if h.settings.Get(message.Chat.ID).ReportsDisabled {
+		return
+	}
+
+	if err := h.submitReport(ctx, message, ""); err != nil {
+		log.Printf("处理 @admin 举报失败: %v", err)
+	}
+}
+
+// handleReportCommand 处理 /report [原因] 命令，需要回复被举报的消息
+func (h *MessageHandler) handleReportCommand(ctx context.Context, message *Message) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if message.ReplyToMessage == nil {
+		return h.sendReply(ctx, message, "❌ 请回复要举报的消息\n用法: /report [原因]")
+	}
+
+	return h.submitReport(ctx, message, commandRemainder(message.Text, 1))
+}
+
+// submitReport 登记举报并通知管理员
+func (h *MessageHandler) submitReport(ctx context.Context, message *Message, reason string) error {
+	// 以频道或群组身份发言时无法记录举报人
+	if message.From == nil || message.SenderChat != nil {
+		return nil
+	}
+
+	chatID := message.Chat.ID
+	if h.settings.Get(chatID).ReportsDisabled {
+		return h.sendReply(ctx, message, "❌ 本群未开启举报功能")
+	}
+
+	reported := message.ReplyToMessage
+	if reported.From == nil || reported.SenderChat != nil {
+		return h.sendReply(ctx, message, "❌ 无法举报以频道或群组身份发送的消息")
+	}
+	if h.botUser != nil && reported.From.ID == h.botUser.ID {
+		return h.sendReply(ctx, message, "❌ 不能举报Bot")
+	}
+	if h.isUserAdmin(ctx, chatID, reported.From.ID) {
+		return h.sendReply(ctx, message, "❌ 不能举报管理员")
+	}
+
+	r := &report{
+		Chat:      message.Chat,
+		MessageID: reported.MessageID,
+		Reporter:  message.From,
+		Target:    reported.From,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+
+	duplicate, wait, err := h.reports.open(r)
+	if err != nil {
+		return fmt.Errorf("登记举报失败: %w", err)
+	}
+	if duplicate {
+		return h.sendReply(ctx, message, "✅ 该消息已被举报，管理员会尽快处理")
+	}
+	if wait > 0 {
+		return h.sendReply(ctx, message, fmt.Sprintf("⏳ 举报过于频繁，请 %d 秒后再试", int(wait.Seconds())+1))
+	}
+
+	notices := h.notifyReport(ctx, r, reported)
+	h.reports.setNotices(r.ID, notices)
+
+	if len(notices) == 0 {
+		return h.sendReply(ctx, message, "⚠️ 已登记举报，但无法通知管理员 (管理员需先私聊启动Bot，或绑定日志频道)")
+	}
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已向管理员举报 %s", getUserName(reported.From)))
+}
+
+// notifyReport 发送举报通知: 绑定了日志频道时发送到频道，否则私聊每位管理员
+func (h *MessageHandler) notifyReport(ctx context.Context, r *report, reported *Message) []reportNotice {
+	params := SendMessageParams{
+		Text:                  formatReport(r, reported),
+		DisableWebPagePreview: true,
+		ReplyMarkup:           reportKeyboard(r),
+	}
+
+	settings := h.settings.Get(r.Chat.ID)
+	if settings.LogChannelID != 0 && settings.IsModuleEnabled(ModuleModLog) {
+		params.ChatID = settings.LogChannelID
+		sent, err := h.client.SendMessage(ctx, params)
+		if err != nil {
+			log.Printf("发送举报到日志频道失败: %v", err)
+			return nil
+		}
+		return []reportNotice{{ChatID: sent.Chat.ID, MessageID: sent.MessageID}}
+	}
+
+	entry, err := h.chatAdmins(ctx, r.Chat.ID)
+	if err != nil {
+		log.Printf("获取管理员列表时出错: %v", err)
+		return nil
+	}
+
+	var notices []reportNotice
+	for _, admin := range entry.admins {
+		if admin.User == nil || admin.User.IsBot {
+			continue
+		}
+
+		params.ChatID = admin.User.ID
+		sent, err := h.client.SendMessage(ctx, params)
+		if err != nil {
+			// 管理员未私聊启动过Bot时无法发送
+			log.Printf("私聊通知管理员 %s 失败: %v", formatUser(admin.User), err)
+			continue
+		}
+		notices = append(notices, reportNotice{ChatID: sent.Chat.ID, MessageID: sent.MessageID})
+	}
+	return notices
+}
+
+// formatReport 格式化举报通知
+func formatReport(r *report, reported *Message) string {
+	var b strings.Builder
+
+	b.WriteString("🚨 #举报\n")
+	b.WriteString(fmt.Sprintf("群组: %s (%d)\n", r.Chat.Title, r.Chat.ID))
+	b.WriteString(fmt.Sprintf("举报人: %s\n", formatUser(r.Reporter)))
+	b.WriteString(fmt.Sprintf("被举报: %s\n", formatUser(r.Target)))
+	if r.Reason != "" {
+		b.WriteString(fmt.Sprintf("原因: %s\n", r.Reason))
+	}
+	if preview := messageText(reported); preview != "" {
+		runes := []rune(preview)
+		if len(runes) > 200 {
+			preview = string(runes[:200]) + "..."
+		}
+		b.WriteString(fmt.Sprintf("内容: %s\n", preview))
+	}
+	if link := messageLink(r.Chat, r.MessageID); link != "" {
+		b.WriteString(fmt.Sprintf("消息: %s\n", link))
+	}
+	b.WriteString(fmt.Sprintf("时间: %s", r.CreatedAt.Format("2006-01-02 15:04:05")))
+
+	return b.String()
+}
+
+// reportKeyboard 生成举报通知的处理按钮
+func reportKeyboard(r *report) *InlineKeyboardMarkup {
+	data := func(action string) string {
+		return reportCallbackPrefix + r.ID + ":" + action
+	}
+	return &InlineKeyboardMarkup{
+		InlineKeyboard: [][]InlineKeyboardButton{
+			{
+				{Text: "🗑 删除消息", CallbackData: data(ReportActionDelete)},
+				{Text: "⚠️ 警告", CallbackData: data(ReportActionWarn)},
+			},
+			{
+				{Text: "🔇 禁言", CallbackData: data(ReportActionMute)},
+				{Text: "🚫 封禁", CallbackData: data(ReportActionBan)},
+			},
+			{
+				{Text: "✅ 忽略", CallbackData: data(ReportActionDismiss)},
+			},
+		},
+	}
+}
+
+// handleReportCallback 处理举报通知中的按钮，data 格式为 <举报ID>:<动作>
+func (h *MessageHandler) handleReportCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	id, action, _ := strings.Cut(data, ":")
+	r, ok := h.reports.get(id)
+	if !ok {
+		return h.answerCallback(ctx, query, "❌ 举报已过期", true)
+	}
+	if r.Handled {
+		return h.answerCallback(ctx, query, "该举报已被其他管理员处理", true)
+	}
+
+	chatID := r.Chat.ID
+	if right, ok := reportActionRights[action]; ok {
+		if !h.memberHasRight(ctx, chatID, query.From.ID, right) {
+			return h.answerCallback(ctx, query, fmt.Sprintf("❌ 您在该群组中没有「%s」权限", adminRightLabels[right]), true)
+		}
+		if !h.botHasRight(ctx, chatID, right) {
+			return h.answerCallback(ctx, query, fmt.Sprintf("❌ Bot在该群组中没有「%s」权限", adminRightLabels[right]), true)
+		}
+	} else if !h.isUserAdmin(ctx, chatID, query.From.ID) {
+		return h.answerCallback(ctx, query, "❌ 您不是该群组的管理员", true)
+	}
+
+	if !h.reports.claim(id) {
+		return h.answerCallback(ctx, query, "该举报已被其他管理员处理", true)
+	}
+
+	result, err := h.applyReportAction(ctx, &r, action, query.From)
+	if err != nil {
+		h.reports.release(id)
+		return h.answerCallback(ctx, query, "❌ 处理失败: "+err.Error(), true)
+	}
+
+	// 更新所有管理员收到的通知并移除按钮
+	for _, notice := range r.Notices {
+		text := formatReportResult(&r, result, query.From)
+		err := h.client.EditMessageText(ctx, EditMessageTextParams{
+			ChatID:                notice.ChatID,
+			MessageID:             notice.MessageID,
+			Text:                  text,
+			DisableWebPagePreview: true,
+		})
+		if err != nil {
+			log.Printf("更新举报通知失败: %v", err)
+		}
+	}
+
+	return h.answerCallback(ctx, query, "✅ "+result, false)
+}
+
+// formatReportResult 格式化已处理的举报
+func formatReportResult(r *report, result string, admin *User) string {
+	text := fmt.Sprintf("🚨 #举报 (已处理)\n群组: %s (%d)\n举报人: %s\n被举报: %s\n",
+		r.Chat.Title, r.Chat.ID, formatUser(r.Reporter), formatUser(r.Target))
+	if r.Reason != "" {
+		text += fmt.Sprintf("原因: %s\n", r.Reason)
+	}
+	if link := messageLink(r.Chat, r.MessageID); link != "" {
+		text += fmt.Sprintf("消息: %s\n", link)
+	}
+	text += fmt.Sprintf("\n✅ %s 已处理: %s", getUserName(admin), result)
+	return text
+}
+
+// applyReportAction 对被举报的成员执行处理动作，返回处理结果描述
+// 禁言和封禁会同时删除被举报的消息
+func (h *MessageHandler) applyReportAction(ctx context.Context, r *report, action string, admin *User) (string, error) {
+	chatID := r.Chat.ID
+	modAction := ModAction{
+		Chat:     r.Chat,
+		Actor:    admin,
+		TargetID: r.Target.ID,
+		Target:   r.Target,
+		Reason:   "成员举报",
+	}
+	if r.Reason != "" {
+		modAction.Reason += ": " + r.Reason
+	}
+
+	switch action {
+	case ReportActionDelete:
+		if err := h.client.DeleteMessage(ctx, chatID, r.MessageID); err != nil {
+			return "", err
+		}
+		return "已删除消息", nil
+
+	case ReportActionWarn:
+		_, err := h.client.SendMessage(ctx, SendMessageParams{
+			ChatID:           chatID,
+			Text:             fmt.Sprintf("⚠️ %s，您的消息被举报，管理员已对您发出警告，请遵守群规", getUserName(r.Target)),
+			ReplyToMessageID: r.MessageID,
+		})
+		if err != nil {
+			return "", err
+		}
+		return "已警告", nil
+
+	case ReportActionMute:
+		modAction.Action = ModActionMute
+		modAction.Duration = reportMuteDuration
+		if err := h.punishMember(ctx, modAction); err != nil {
+			return "", err
+		}
+		h.deleteReportedMessage(ctx, r)
+		return "已禁言 " + formatDuration(reportMuteDuration), nil
+
+	case ReportActionBan:
+		modAction.Action = ModActionBan
+		if err := h.punishMember(ctx, modAction); err != nil {
+			return "", err
+		}
+		h.deleteReportedMessage(ctx, r)
+		return "已封禁", nil
+
+	case ReportActionDismiss:
+		return "已忽略", nil
+	}
+
+	return "", fmt.Errorf("未知的动作: %s", action)
+}
+
+// deleteReportedMessage 删除被举报的消息，失败只记录日志 (消息可能已被删除)
+func (h *MessageHandler) deleteReportedMessage(ctx context.Context, r *report) {
+	if err := h.client.DeleteMessage(ctx, r.Chat.ID, r.MessageID); err != nil {
+		log.Printf("删除被举报的消息失败: %v", err)
+	}
+}
+
+// handleReportsCommand 处理 /reports on|off 命令，开关本群的举报功能
+func (h *MessageHandler) handleReportsCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	if len(args) == 0 {
+		disabled := h.settings.Get(message.Chat.ID).ReportsDisabled
+		return h.sendReply(ctx, message, fmt.Sprintf("🚨 举报功能: %s\n用法: /reports on|off", onOff(!disabled)))
+	}
+
+	var disabled bool
+	switch strings.ToLower(args[0]) {
+	case "on":
+		disabled = false
+	case "off":
+		disabled = true
+	default:
+		return h.sendReply(ctx, message, "❌ 用法: /reports on|off")
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		s.ReportsDisabled = disabled
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	if disabled {
+		return h.sendReply(ctx, message, "✅ 已关闭举报功能")
+	}
+	return h.sendReply(ctx, message, "✅ 已开启举报功能，成员可回复消息发送 /report 或 @admin 举报")
+}
@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+)
+
+// AdminRight 管理员权限名称 (与 ChatMember 的 JSON 字段名一致)
+type AdminRight string
+
+// 管理命令需要检查的管理员权限
+const (
+	RightManageChat      AdminRight = "can_manage_chat"
+	RightDeleteMessages  AdminRight = "can_delete_messages"
+	RightManageVideoChat AdminRight = "can_manage_video_chats"
+	RightRestrictMembers AdminRight = "can_restrict_members"
+	RightPromoteMembers  AdminRight = "can_promote_members"
+	RightChangeInfo      AdminRight = "can_change_info"
+	RightInviteUsers     AdminRight = "can_invite_users"
+	RightPinMessages     AdminRight = "can_pin_messages"
+	RightPostMessages    AdminRight = "can_post_messages"
+	RightEditMessages    AdminRight = "can_edit_messages"
+	RightManageTopics    AdminRight = "can_manage_topics"
+)
+
+// adminRightLabels 管理员权限显示名称
+var adminRightLabels = map[AdminRight]string{
+	RightManageChat:      "管理群组",
+	RightDeleteMessages:  "删除消息",
+	RightManageVideoChat: "管理视频聊天",
+	RightRestrictMembers: "限制成员",
+	RightPromoteMembers:  "添加管理员",
+	RightChangeInfo:      "修改群组信息",
+	RightInviteUsers:     "邀请用户",
+	RightPinMessages:     "置顶消息",
+	RightPostMessages:    "发布消息",
+	RightEditMessages:    "编辑消息",
+	RightManageTopics:    "管理话题",
+}
+
+// IsAdmin 成员是否为群主或管理员
+func (m *ChatMember) IsAdmin() bool {
+	return m.Status == MemberStatusCreator || m.Status == MemberStatusAdministrator
+}
+
+// HasRight 成员是否拥有指定管理员权限 (群主拥有全部权限)
+func (m *ChatMember) HasRight(right AdminRight) bool {
+	if m.Status == MemberStatusCreator {
+		return true
+	}
+	if m.Status != MemberStatusAdministrator {
+		return false
+	}
+
+	switch right {
+	case RightManageChat:
+		return m.CanManageChat
+	case RightDeleteMessages:
+		return m.CanDeleteMessages
+	case RightManageVideoChat:
+		return m.CanManageVideoChats
+	case RightRestrictMembers:
+		return m.CanRestrictMembers
+	case RightPromoteMembers:
+		return m.CanPromoteMembers
+	case RightChangeInfo:
+		return m.CanChangeInfo
+	case RightInviteUsers:
+		return m.CanInviteUsers
+	case RightPinMessages:
+		return m.CanPinMessages
+	case RightPostMessages:
+		return m.CanPostMessages
+	case RightEditMessages:
+		return m.CanEditMessages
+	case RightManageTopics:
+		return m.CanManageTopics
+	}
+	return false
+}
+
+// memberHasRight 检查用户在聊天中是否拥有指定管理员权限
+func (h *MessageHandler) memberHasRight(ctx context.Context, chatID, userID int64, right AdminRight) bool {
+	member, ok := h.adminMember(ctx, chatID, userID)
+	return ok && member.HasRight(right)
+}
+
+// requireRight 检查命令发送者和Bot自身是否都拥有指定管理员权限
+// 不满足时直接回复提示，返回 false 和回复的发送结果
+func (h *MessageHandler) requireRight(ctx context.Context, message *Message, right AdminRight) (bool, error) {
+	label := adminRightLabels[right]
+
+	// 匿名管理员以群组身份发言，需要点击按钮确认具体是哪位管理员
+	if isAnonymousAdmin(message) {
+		return false, h.requestAnonAdminConfirmation(ctx, message, right)
+	}
+
+	if !h.memberHasRight(ctx, message.Chat.ID, message.From.ID, right) {
+		return false, h.sendReply(ctx, message, fmt.Sprintf("❌ 您没有「%s」权限", label))
+	}
+
+	if !h.botHasRight(ctx, message.Chat.ID, right) {
+		return false, h.sendReply(ctx, message, fmt.Sprintf("❌ Bot没有「%s」权限，请先在群组设置中授予", label))
+	}
+
+	return true, nil
+}
+
+// botHasRight 检查Bot自身在聊天中是否拥有指定管理员权限
+func (h *MessageHandler) botHasRight(ctx context.Context, chatID int64, right AdminRight) bool {
+	if h.botUser == nil {
+		return false
+	}
+	return h.memberHasRight(ctx, chatID, h.botUser.ID, right)
+}
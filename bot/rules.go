@@ -0,0 +1,624 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// 转发规则支持的内容类型
+const (
+	ContentText     = "text"
+	ContentPhoto    = "photo"
+	ContentVideo    = "video"
+	ContentDocument = "document"
+	ContentLink     = "link"
+)
+
+// 转发方式
+const (
+	RuleModeForward = "forward" // 保留来源的转发
+	RuleModeCopy    = "copy"    // 不带来源的复制，可使用说明模板
+)
+
+// ForwardRule 自动转发规则
+type ForwardRule struct {
+	ID           int     `json:"id"`
+	SourceChatID int64   `json:"source_chat_id"`
+	SenderID     int64   `json:"sender_id,omitempty"`
+	ContentType  string  `json:"content_type,omitempty"`
+	Keyword      string  `json:"keyword,omitempty"`
+	Regex        string  `json:"regex,omitempty"`
+	Hashtag      string  `json:"hashtag,omitempty"`
+	Targets      []int64 `json:"targets"`
+	Mode         string  `json:"mode"`
+	Caption      string  `json:"caption,omitempty"` // 说明模板，仅复制方式生效
+	Enabled      bool    `json:"enabled"`
+	CreatedBy    int64   `json:"created_by"`
+}
+
+// ruleData 转发规则的持久化结构
+type ruleData struct {
+	NextID int            `json:"next_id"`
+	Rules  []*ForwardRule `json:"rules"`
+}
+
+// RuleManager 管理转发规则并负责匹配
+type RuleManager struct {
+	mu       sync.RWMutex
+	storage  *Storage
+	data     ruleData
+	compiled map[int]*regexp.Regexp
+}
+
+// NewRuleManager 创建规则管理器并从存储中加载已有规则
+func NewRuleManager(storage *Storage) (*RuleManager, error) {
+	m := &RuleManager{
+		storage:  storage,
+		data:     ruleData{NextID: 1},
+		compiled: make(map[int]*regexp.Regexp),
+	}
+
+	if err := storage.Load("rules", &m.data); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range m.data.Rules {
+		if rule.Regex == "" {
+			continue
+		}
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			log.Printf("规则 #%d 的正则表达式无效，已忽略: %v", rule.ID, err)
+			continue
+		}
+		m.compiled[rule.ID] = re
+	}
+
+	return m, nil
+}
+
+// Add 添加规则并保存
+func (m *RuleManager) Add(rule ForwardRule) (*ForwardRule, error) {
+	var re *regexp.Regexp
+	if rule.Regex != "" {
+		var err error
+		if re, err = regexp.Compile(rule.Regex); err != nil {
+			return nil, fmt.Errorf("正则表达式无效: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rule.ID = m.data.NextID
+	data := ruleData{
+		NextID: m.data.NextID + 1,
+		Rules:  append(m.data.cloneRules(), &rule),
+	}
+	if err := m.commit(data); err != nil {
+		return nil, err
+	}
+	if re != nil {
+		m.compiled[rule.ID] = re
+	}
+
+	saved := rule
+	return &saved, nil
+}
+
+// Delete 删除聊天中的规则
+func (m *RuleManager) Delete(chatID int64, id int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, rule := range m.data.Rules {
+		if rule.ID == id && rule.SourceChatID == chatID {
+			rules := m.data.cloneRules()
+			data := ruleData{
+				NextID: m.data.NextID,
+				Rules:  append(rules[:i], rules[i+1:]...),
+			}
+			if err := m.commit(data); err != nil {
+				return false, err
+			}
+			delete(m.compiled, id)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Toggle 切换聊天中规则的启用状态
+func (m *RuleManager) Toggle(chatID int64, id int) (*ForwardRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, rule := range m.data.Rules {
+		if rule.ID == id && rule.SourceChatID == chatID {
+			toggled := *rule
+			toggled.Enabled = !toggled.Enabled
+
+			data := ruleData{NextID: m.data.NextID, Rules: m.data.cloneRules()}
+			data.Rules[i] = &toggled
+			if err := m.commit(data); err != nil {
+				return nil, err
+			}
+			saved := toggled
+			return &saved, nil
+		}
+	}
+
+	return nil, errors.New("规则不存在")
+}
+
+// cloneRules 复制规则列表 (规则本身共享，修改规则时需替换为副本)
+func (d ruleData) cloneRules() []*ForwardRule {
+	return append([]*ForwardRule(nil), d.Rules...)
+}
+
+// commit 保存新的规则数据，保存成功后才替换内存中的数据，失败时保持原样
+func (m *RuleManager) commit(data ruleData) error {
+	if err := m.storage.Save("rules", data); err != nil {
+		return err
+	}
+	m.data = data
+	return nil
+}
+
+// List 列出聊天中的所有规则
+func (m *RuleManager) List(chatID int64) []ForwardRule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rules []ForwardRule
+	for _, rule := range m.data.Rules {
+		if rule.SourceChatID == chatID {
+			rules = append(rules, *rule)
+		}
+	}
+	return rules
+}
+
+// Match 返回消息命中的所有已启用规则
+func (m *RuleManager) Match(message *Message) []ForwardRule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []ForwardRule
+	for _, rule := range m.data.Rules {
+		if !rule.Enabled || rule.SourceChatID != message.Chat.ID {
+			continue
+		}
+		if m.matches(rule, message) {
+			matched = append(matched, *rule)
+		}
+	}
+	return matched
+}
+
+// matches 检查单条规则的所有条件
+func (m *RuleManager) matches(rule *ForwardRule, message *Message) bool {
+	if rule.SenderID != 0 && (message.From == nil || message.From.ID != rule.SenderID) {
+		return false
+	}
+
+	if rule.ContentType != "" && !hasContentType(message, rule.ContentType) {
+		return false
+	}
+
+	text := messageText(message)
+
+	if rule.Keyword != "" && !strings.Contains(strings.ToLower(text), strings.ToLower(rule.Keyword)) {
+		return false
+	}
+
+	if rule.Regex != "" {
+		re, ok := m.compiled[rule.ID]
+		if !ok || !re.MatchString(text) {
+			return false
+		}
+	}
+
+	if rule.Hashtag != "" && !hasHashtag(message, rule.Hashtag) {
+		return false
+	}
+
+	return true
+}
+
+// messageText 返回消息的文本或媒体说明
+func messageText(message *Message) string {
+	if message.Text != "" {
+		return message.Text
+	}
+	return message.Caption
+}
+
+// messageEntities 返回消息文本或说明对应的实体
+func messageEntities(message *Message) []MessageEntity {
+	if message.Text != "" {
+		return message.Entities
+	}
+	return message.CaptionEntities
+}
+
+// entityText 取出实体覆盖的文本 (实体的偏移量以UTF-16编码单位计算)
+func entityText(text string, entity MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	end := entity.Offset + entity.Length
+	if entity.Offset < 0 || end > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[entity.Offset:end]))
+}
+
+// hasContentType 检查消息是否属于指定内容类型
+func hasContentType(message *Message, contentType string) bool {
+	switch contentType {
+	case ContentPhoto:
+		return len(message.Photo) > 0
+	case ContentVideo:
+		return message.Video != nil
+	case ContentDocument:
+		return message.Document != nil
+	case ContentLink:
+		for _, entity := range messageEntities(message) {
+			if entity.Type == "url" || entity.Type == "text_link" {
+				return true
+			}
+		}
+		return false
+	case ContentText:
+		return message.Text != ""
+	}
+	return false
+}
+
+// hasHashtag 检查消息是否包含指定话题标签 (不区分大小写)
+func hasHashtag(message *Message, hashtag string) bool {
+	want := strings.ToLower(strings.TrimPrefix(hashtag, "#"))
+	text := messageText(message)
+
+	for _, entity := range messageEntities(message) {
+		if entity.Type != "hashtag" {
+			continue
+		}
+		if strings.ToLower(strings.TrimPrefix(entityText(text, entity), "#")) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// applyForwardRules 对普通消息执行所有命中的转发规则
+func (h *MessageHandler) applyForwardRules(ctx context.Context, message *Message) {
+	if !h.settings.Get(message.Chat.ID).IsModuleEnabled(ModuleForward) {
+		return
+	}
+
+	// 相册需要等所有部分到齐后整体转发
+	if message.MediaGroupID != "" {
+		h.albums.Add(ctx, message)
+		return
+	}
+
+	for _, rule := range h.rules.Match(message) {
+		for _, target := range rule.Targets {
+			if err := h.forwardByRule(ctx, rule, message, target); err != nil {
+				log.Printf("规则 #%d 转发到 %d 失败: %v", rule.ID, target, err)
+			}
+		}
+	}
+}
+
+// forwardByRule 按规则的转发方式把消息发送到目标聊天
+func (h *MessageHandler) forwardByRule(ctx context.Context, rule ForwardRule, message *Message, target int64) error {
+	if rule.Mode != RuleModeCopy {
+		_, err := h.client.ForwardMessage(ctx, ForwardMessageParams{
+			ChatID:     target,
+			FromChatID: message.Chat.ID,
+			MessageID:  message.MessageID,
+		})
+		return err
+	}
+
+	if rule.Caption == "" {
+		_, err := h.client.CopyMessage(ctx, CopyMessageParams{
+			ChatID:     target,
+			FromChatID: message.Chat.ID,
+			MessageID:  message.MessageID,
+		})
+		return err
+	}
+
+	caption := renderCaptionTemplate(rule.Caption, message)
+
+	// 纯文本消息没有说明，直接发送渲染后的模板
+	if message.Text != "" {
+		_, err := h.client.SendMessage(ctx, SendMessageParams{
+			ChatID: target,
+			Text:   caption,
+		})
+		return err
+	}
+
+	_, err := h.client.CopyMessage(ctx, CopyMessageParams{
+		ChatID:     target,
+		FromChatID: message.Chat.ID,
+		MessageID:  message.MessageID,
+		Caption:    &caption,
+	})
+	return err
+}
+
+// renderCaptionTemplate 渲染说明模板
+// 支持的占位符: {text} 原文或原说明, {sender} 发送者, {chat} 来源群组, {link} 原消息链接
+func renderCaptionTemplate(template string, message *Message) string {
+	replacer := strings.NewReplacer(
+		"{text}", messageText(message),
+		"{sender}", getUserName(message.From),
+		"{chat}", message.Chat.Title,
+		"{link}", messageLink(message.Chat, message.MessageID),
+		`\n`, "\n",
+	)
+	return replacer.Replace(template)
+}
+
+// rulesCallbackPrefix /rules 列表按钮的 callback_data 前缀
+const rulesCallbackPrefix = "rules:"
+
+// handleAddRuleCommand 处理 /addrule 命令
+// 用法: /addrule to=<群组ID,...> [type=photo] [from=<用户ID>] [keyword=<关键词>] [regex=<正则>] [tag=<#话题>] [mode=copy] [caption=<模板>]
+func (h *MessageHandler) handleAddRuleCommand(ctx context.Context, message *Message) error {
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	rule, err := parseForwardRule(message.Text)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ "+err.Error()+"\n用法: /addrule to=<群组ID,...> [type=text|photo|video|document|link] [from=<用户ID>] [keyword=<关键词>] [regex=<正则>] [tag=<#话题>] [mode=forward|copy] [caption=<模板>]")
+	}
+
+	if len(rule.Targets) == 0 && h.defaultForwardTarget != 0 {
+		rule.Targets = []int64{h.defaultForwardTarget}
+	}
+	if len(rule.Targets) == 0 {
+		return h.sendReply(ctx, message, "❌ 请使用 to=<群组ID> 指定转发目标")
+	}
+
+	// 必须同时是所有目标群组的管理员，防止向无权管理的聊天转发消息
+	for _, id := range rule.Targets {
+		if !h.isUserAdmin(ctx, id, message.From.ID) {
+			return h.sendReply(ctx, message, fmt.Sprintf("❌ 您不是 %d 的管理员", id))
+		}
+	}
+
+	rule.SourceChatID = message.Chat.ID
+	rule.CreatedBy = message.From.ID
+	rule.Enabled = true
+
+	saved, err := h.rules.Add(*rule)
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 添加规则失败: "+err.Error())
+	}
+
+	return h.sendReply(ctx, message, "✅ 已添加规则\n"+describeRule(*saved))
+}
+
+// parseForwardRule 解析 /addrule 的 key=value 参数
+// caption= 会占用其后的全部文本，因此需要放在最后
+func parseForwardRule(text string) (*ForwardRule, error) {
+	rule := &ForwardRule{Mode: RuleModeForward}
+
+	parts := strings.SplitN(text, " ", 2)
+	if len(parts) < 2 {
+		return nil, errors.New("缺少规则参数")
+	}
+	rest := strings.TrimSpace(parts[1])
+
+	if idx := strings.Index(rest, "caption="); idx >= 0 {
+		rule.Caption = strings.TrimSpace(rest[idx+len("caption="):])
+		rest = rest[:idx]
+	}
+
+	for _, field := range strings.Fields(rest) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("无效的参数: %s", field)
+		}
+
+		key, value := strings.ToLower(kv[0]), kv[1]
+		switch key {
+		case "to":
+			for _, idStr := range strings.Split(value, ",") {
+				id, err := strconv.ParseInt(idStr, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("无效的群组ID: %s", idStr)
+				}
+				rule.Targets = append(rule.Targets, id)
+			}
+		case "from":
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("无效的用户ID: %s", value)
+			}
+			rule.SenderID = id
+		case "type":
+			switch value {
+			case ContentText, ContentPhoto, ContentVideo, ContentDocument, ContentLink:
+				rule.ContentType = value
+			default:
+				return nil, fmt.Errorf("不支持的内容类型: %s", value)
+			}
+		case "keyword":
+			rule.Keyword = value
+		case "regex":
+			rule.Regex = value
+		case "tag":
+			rule.Hashtag = strings.TrimPrefix(value, "#")
+		case "mode":
+			if value != RuleModeForward && value != RuleModeCopy {
+				return nil, fmt.Errorf("不支持的转发方式: %s", value)
+			}
+			rule.Mode = value
+		default:
+			return nil, fmt.Errorf("未知参数: %s", key)
+		}
+	}
+
+	if rule.Caption != "" {
+		rule.Mode = RuleModeCopy
+	}
+
+	return rule, nil
+}
+
+// describeRule 生成规则的可读描述
+func describeRule(rule ForwardRule) string {
+	var conds []string
+	if rule.SenderID != 0 {
+		conds = append(conds, fmt.Sprintf("发送者=%d", rule.SenderID))
+	}
+	if rule.ContentType != "" {
+		conds = append(conds, "类型="+rule.ContentType)
+	}
+	if rule.Keyword != "" {
+		conds = append(conds, "关键词="+rule.Keyword)
+	}
+	if rule.Regex != "" {
+		conds = append(conds, "正则="+rule.Regex)
+	}
+	if rule.Hashtag != "" {
+		conds = append(conds, "话题=#"+rule.Hashtag)
+	}
+	if len(conds) == 0 {
+		conds = append(conds, "全部消息")
+	}
+
+	targets := make([]string, len(rule.Targets))
+	for i, t := range rule.Targets {
+		targets[i] = strconv.FormatInt(t, 10)
+	}
+
+	desc := fmt.Sprintf("#%d %s [%s] %s → %s",
+		rule.ID, checkMark(rule.Enabled), rule.Mode, strings.Join(conds, ", "), strings.Join(targets, ","))
+	if rule.Caption != "" {
+		desc += "\n   说明模板: " + rule.Caption
+	}
+	return desc
+}
+
+// handleRulesCommand 处理 /rules 命令
+func (h *MessageHandler) handleRulesCommand(ctx context.Context, message *Message) error {
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	text, markup := renderRulesList(h.rules.List(message.Chat.ID))
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID:      message.Chat.ID,
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	return err
+}
+
+// renderRulesList 渲染规则列表及启用/停用按钮
+func renderRulesList(rules []ForwardRule) (string, interface{}) {
+	if len(rules) == 0 {
+		return "📭 本群暂无转发规则\n使用 /addrule 添加", nil
+	}
+
+	var text strings.Builder
+	text.WriteString("📤 转发规则列表:\n\n")
+
+	var rows [][]InlineKeyboardButton
+	for _, rule := range rules {
+		text.WriteString(describeRule(rule) + "\n")
+
+		label := fmt.Sprintf("停用 #%d", rule.ID)
+		if !rule.Enabled {
+			label = fmt.Sprintf("启用 #%d", rule.ID)
+		}
+		rows = append(rows, []InlineKeyboardButton{{
+			Text:         label,
+			CallbackData: fmt.Sprintf("%stoggle:%d", rulesCallbackPrefix, rule.ID),
+		}})
+	}
+
+	return text.String(), &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// handleRulesCallback 处理规则列表中的启用/停用按钮
+func (h *MessageHandler) handleRulesCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return h.answerCallback(ctx, query, "", false)
+	}
+
+	chatID := query.Message.Chat.ID
+	if !h.isUserAdmin(ctx, chatID, query.From.ID) {
+		return h.answerCallback(ctx, query, "❌ 只有管理员可以修改规则", true)
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(data, "toggle:"))
+	if err != nil {
+		return h.answerCallback(ctx, query, "❌ 无效的规则", true)
+	}
+
+	rule, err := h.rules.Toggle(chatID, id)
+	if err != nil {
+		return h.answerCallback(ctx, query, "❌ "+err.Error(), true)
+	}
+
+	text, markup := renderRulesList(h.rules.List(chatID))
+	err = h.client.EditMessageText(ctx, EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   query.Message.MessageID,
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		log.Printf("更新规则列表失败: %v", err)
+	}
+
+	status := "已停用"
+	if rule.Enabled {
+		status = "已启用"
+	}
+	return h.answerCallback(ctx, query, fmt.Sprintf("规则 #%d %s", rule.ID, status), false)
+}
+
+// handleDelRuleCommand 处理 /delrule 命令
+func (h *MessageHandler) handleDelRuleCommand(ctx context.Context, message *Message, args []string) error {
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	if len(args) == 0 {
+		return h.sendReply(ctx, message, "❌ 请指定规则编号\n用法: /delrule <编号>")
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return h.sendReply(ctx, message, "❌ 无效的规则编号")
+	}
+
+	deleted, err := h.rules.Delete(message.Chat.ID, id)
+	if err != nil {
+		log.Printf("保存转发规则失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 删除规则失败")
+	}
+	if !deleted {
+		return h.sendReply(ctx, message, "❌ 规则不存在")
+	}
+
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已删除规则 #%d", id))
+}
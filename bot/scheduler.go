@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// schedulerTick 调度器检查到期任务的间隔
+const schedulerTick = 30 * time.Second
+
+// scheduledJob 周期执行的后台任务
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context)
+	next     time.Time
+}
+
+// scheduler 按固定间隔执行后台任务 (夜间模式切换、过期数据清理等)
+// 所有任务在同一个协程中依次执行
+type scheduler struct {
+	mu   sync.Mutex
+	jobs []*scheduledJob
+}
+
+// newScheduler 创建调度器
+func newScheduler() *scheduler {
+	return &scheduler{}
+}
+
+// every 注册一个每隔 interval 执行一次的任务，调度器启动后立即执行第一次
+func (s *scheduler) every(name string, interval time.Duration, run func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, &scheduledJob{
+		name:     name,
+		interval: interval,
+		run:      run,
+	})
+}
+
+// start 运行调度器，直到 ctx 被取消
+func (s *scheduler) start(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	s.runDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runDue(ctx)
+		}
+	}
+}
+
+// runDue 执行所有到期的任务
+func (s *scheduler) runDue(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]*scheduledJob(nil), s.jobs...)
+	s.mu.Unlock()
+
+	now := time.Now()
+	for _, job := range jobs {
+		if now.Before(job.next) {
+			continue
+		}
+		job.next = now.Add(job.interval)
+
+		start := time.Now()
+		job.run(ctx)
+		if elapsed := time.Since(start); elapsed > schedulerTick {
+			log.Printf("定时任务 %s 执行耗时过长: %v", job.name, elapsed)
+		}
+	}
+}
+
+// RunScheduler 启动后台定时任务，直到 ctx 被取消
+func (h *MessageHandler) RunScheduler(ctx context.Context) {
+	h.scheduler.start(ctx)
+}
@@ -0,0 +1,553 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 可在 /settings 中开关的功能模块
+const (
+	ModuleForward   = "forward"
+	ModuleAntiFlood = "antiflood"
+	ModuleModLog    = "modlog"
+)
+
+// moduleInfo 功能模块描述
+type moduleInfo struct {
+	Key            string
+	Label          string
+	DefaultEnabled bool
+}
+
+// knownModules 所有功能模块 (按菜单显示顺序)
+var knownModules = []moduleInfo{
+	{Key: ModuleForward, Label: "自动转发", DefaultEnabled: true},
+	{Key: ModuleAntiFlood, Label: "防刷屏", DefaultEnabled: false},
+	{Key: ModuleModLog, Label: "管理日志", DefaultEnabled: true},
+}
+
+// supportedLanguages 支持的界面语言
+var supportedLanguages = []struct {
+	Code  string
+	Label string
+}{
+	{Code: "zh", Label: "简体中文"},
+	{Code: "en", Label: "English"},
+}
+
+// 防刷屏触发后的处理动作
+const (
+	FloodActionMute   = "mute"
+	FloodActionKick   = "kick"
+	FloodActionBan    = "ban"
+	FloodActionDelete = "delete"
+)
+
+// floodActions 防刷屏动作 (按菜单切换顺序)
+var floodActions = []string{FloodActionMute, FloodActionKick, FloodActionBan, FloodActionDelete}
+
+// floodActionLabels 防刷屏动作显示名称
+var floodActionLabels = map[string]string{
+	FloodActionMute:   "禁言",
+	FloodActionKick:   "踢出",
+	FloodActionBan:    "封禁",
+	FloodActionDelete: "删除消息",
+}
+
+// FloodSettings 防刷屏阈值配置
+type FloodSettings struct {
+	Limit        int    `json:"limit"`  // 时间窗口内允许的最大消息数
+	Window       int    `json:"window"` // 时间窗口 (秒)
+	Action       string `json:"action"`
+	MuteDuration int64  `json:"mute_duration,omitempty"` // 禁言时长 (秒)
+}
+
+// ChatSettings 单个聊天的运行时配置
+type ChatSettings struct {
+	ChatID         int64           `json:"chat_id"`
+	Language       string          `json:"language"`
+	Modules        map[string]bool `json:"modules,omitempty"`
+	Flood          FloodSettings   `json:"flood"`
+	WelcomeEnabled bool            `json:"welcome_enabled"`
+	LogChannelID   int64           `json:"log_channel_id,omitempty"`
+	ForwardTargets []int64         `json:"forward_targets,omitempty"`
+	Locks          map[string]bool `json:"locks,omitempty"`
+	AllowedDomains []string        `json:"allowed_domains,omitempty"`
+	AntiChannelPin bool            `json:"anti_channel_pin,omitempty"`
+
+	// 只读模式开启前的成员权限，关闭时按原样恢复
+	ReadOnly         bool             `json:"read_only,omitempty"`
+	SavedPermissions *ChatPermissions `json:"saved_permissions,omitempty"`
+
+	NightMode *NightModeSettings `json:"night_mode,omitempty"`
+	SlowMode  int                `json:"slow_mode,omitempty"` // 慢速模式间隔 (秒)，0 表示关闭
+
+	ReportsDisabled bool `json:"reports_disabled,omitempty"` // 关闭 /report 和 @admin 举报
+	GlobalBanOptOut bool `json:"gban_opt_out,omitempty"`     // 不在本群执行全局封禁
+	PrivateNotes    bool `json:"private_notes,omitempty"`    // 笔记私聊发送给请求者
+}
+
+// defaultChatSettings 返回聊天的默认配置
+func defaultChatSettings(chatID int64) *ChatSettings {
+	return &ChatSettings{
+		ChatID:   chatID,
+		Language: "zh",
+		Modules:  map[string]bool{},
+		Flood: FloodSettings{
+			Limit:        5,
+			Window:       3,
+			Action:       FloodActionMute,
+			MuteDuration: int64(floodDefaultMuteDuration / time.Second),
+		},
+	}
+}
+
+// IsModuleEnabled 检查功能模块是否启用
+func (s *ChatSettings) IsModuleEnabled(module string) bool {
+	if enabled, ok := s.Modules[module]; ok {
+		return enabled
+	}
+
+	for _, m := range knownModules {
+		if m.Key == module {
+			return m.DefaultEnabled
+		}
+	}
+	return false
+}
+
+// clone 深拷贝配置，避免调用方修改共享状态
+func (s *ChatSettings) clone() *ChatSettings {
+	c := *s
+	c.Modules = make(map[string]bool, len(s.Modules))
+	for k, v := range s.Modules {
+		c.Modules[k] = v
+	}
+	c.ForwardTargets = append([]int64(nil), s.ForwardTargets...)
+	c.Locks = make(map[string]bool, len(s.Locks))
+	for k, v := range s.Locks {
+		c.Locks[k] = v
+	}
+	c.AllowedDomains = append([]string(nil), s.AllowedDomains...)
+	if s.SavedPermissions != nil {
+		saved := *s.SavedPermissions
+		c.SavedPermissions = &saved
+	}
+	if s.NightMode != nil {
+		c.NightMode = s.NightMode.clone()
+	}
+	return &c
+}
+
+// SettingsManager 管理所有聊天的配置并负责持久化
+type SettingsManager struct {
+	mu       sync.RWMutex
+	storage  *Storage
+	settings map[int64]*ChatSettings
+}
+
+// NewSettingsManager 创建配置管理器并从存储中加载已有配置
+func NewSettingsManager(storage *Storage) (*SettingsManager, error) {
+	m := &SettingsManager{
+		storage:  storage,
+		settings: make(map[int64]*ChatSettings),
+	}
+
+	if err := storage.Load("settings", &m.settings); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Get 获取聊天配置的副本 (不存在时返回默认配置)
+func (m *SettingsManager) Get(chatID int64) *ChatSettings {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if s, ok := m.settings[chatID]; ok {
+		return s.clone()
+	}
+	return defaultChatSettings(chatID)
+}
+
+// List 获取所有已保存的聊天配置的副本
+func (m *SettingsManager) List() []*ChatSettings {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]*ChatSettings, 0, len(m.settings))
+	for _, s := range m.settings {
+		list = append(list, s.clone())
+	}
+	return list
+}
+
+// Update 修改聊天配置并保存
+// 修改作用在副本上，保存成功后才替换内存中的配置，保存失败时内存与磁盘保持一致
+func (m *SettingsManager) Update(chatID int64, fn func(s *ChatSettings)) (*ChatSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.settings[chatID]
+	var s *ChatSettings
+	if ok {
+		s = old.clone()
+	} else {
+		s = defaultChatSettings(chatID)
+	}
+	if s.Modules == nil {
+		s.Modules = map[string]bool{}
+	}
+	if s.Locks == nil {
+		s.Locks = map[string]bool{}
+	}
+
+	fn(s)
+
+	m.settings[chatID] = s
+	if err := m.storage.Save("settings", m.settings); err != nil {
+		if ok {
+			m.settings[chatID] = old
+		} else {
+			delete(m.settings, chatID)
+		}
+		return nil, err
+	}
+
+	return s.clone(), nil
+}
+
+// settingsCallbackPrefix /settings 菜单按钮的 callback_data 前缀
+const settingsCallbackPrefix = "settings:"
+
+// handleSettingsCommand 处理 /settings 命令
+func (h *MessageHandler) handleSettingsCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if !h.isSenderAdmin(ctx, message) {
+		return h.sendReply(ctx, message, "❌ 您没有管理员权限")
+	}
+
+	// /settings addtarget <群组ID> 添加默认转发目标
+	if len(args) >= 2 && strings.ToLower(args[0]) == "addtarget" {
+		targetID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return h.sendReply(ctx, message, "❌ 无效的群组ID")
+		}
+
+		// 调用者和Bot都必须是目标群组的管理员，防止向无权管理的聊天转发消息
+		if message.From == nil || !h.isUserAdmin(ctx, targetID, message.From.ID) {
+			return h.sendReply(ctx, message, fmt.Sprintf("❌ 您不是 %d 的管理员", targetID))
+		}
+		if h.botUser == nil || !h.isUserAdmin(ctx, targetID, h.botUser.ID) {
+			return h.sendReply(ctx, message, fmt.Sprintf("❌ Bot不是 %d 的管理员", targetID))
+		}
+
+		_, err = h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+			for _, id := range s.ForwardTargets {
+				if id == targetID {
+					return
+				}
+			}
+			s.ForwardTargets = append(s.ForwardTargets, targetID)
+		})
+		if err != nil {
+			log.Printf("保存群组配置失败: %v", err)
+			return h.sendReply(ctx, message, "❌ 保存配置失败")
+		}
+
+		return h.sendReply(ctx, message, fmt.Sprintf("✅ 已添加转发目标: %d", targetID))
+	}
+
+	text, markup := renderSettingsPage(h.settings.Get(message.Chat.ID), "home")
+	_, err := h.client.SendMessage(ctx, SendMessageParams{
+		ChatID:      message.Chat.ID,
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	return err
+}
+
+// handleSettingsCallback 处理 /settings 菜单的按钮点击
+// data 格式: <动作>[:<参数>]，例如 page:flood、mod:forward、flood:limit:+1
+func (h *MessageHandler) handleSettingsCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return h.answerCallback(ctx, query, "", false)
+	}
+
+	chatID := query.Message.Chat.ID
+	if !h.isUserAdmin(ctx, chatID, query.From.ID) {
+		return h.answerCallback(ctx, query, "❌ 只有管理员可以修改设置", true)
+	}
+
+	parts := strings.Split(data, ":")
+	page := "home"
+	var update func(s *ChatSettings)
+
+	switch parts[0] {
+	case "close":
+		if err := h.client.DeleteMessage(ctx, chatID, query.Message.MessageID); err != nil {
+			log.Printf("关闭设置菜单失败: %v", err)
+		}
+		return h.answerCallback(ctx, query, "", false)
+	case "page":
+		if len(parts) > 1 {
+			page = parts[1]
+		}
+	case "lang":
+		page = "lang"
+		if len(parts) > 1 && isSupportedLanguage(parts[1]) {
+			code := parts[1]
+			update = func(s *ChatSettings) { s.Language = code }
+		}
+	case "mod":
+		page = "modules"
+		if len(parts) > 1 && isKnownModule(parts[1]) {
+			module := parts[1]
+			update = func(s *ChatSettings) { s.Modules[module] = !s.IsModuleEnabled(module) }
+		}
+	case "flood":
+		page = "flood"
+		if len(parts) > 1 {
+			update = floodSettingsUpdate(parts[1:])
+		}
+	case "welcome":
+		update = func(s *ChatSettings) { s.WelcomeEnabled = !s.WelcomeEnabled }
+	case "unlog":
+		page = "log"
+		update = func(s *ChatSettings) { s.LogChannelID = 0 }
+	case "deltarget":
+		page = "targets"
+		if len(parts) > 1 {
+			targetID, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				return h.answerCallback(ctx, query, "❌ 无效的群组ID", true)
+			}
+			update = func(s *ChatSettings) {
+				targets := s.ForwardTargets[:0]
+				for _, id := range s.ForwardTargets {
+					if id != targetID {
+						targets = append(targets, id)
+					}
+				}
+				s.ForwardTargets = targets
+			}
+		}
+	}
+
+	settings := h.settings.Get(chatID)
+	if update != nil {
+		updated, err := h.settings.Update(chatID, update)
+		if err != nil {
+			log.Printf("保存群组配置失败: %v", err)
+			return h.answerCallback(ctx, query, "❌ 保存配置失败", true)
+		}
+		settings = updated
+	}
+
+	text, markup := renderSettingsPage(settings, page)
+	err := h.client.EditMessageText(ctx, EditMessageTextParams{
+		ChatID:      chatID,
+		MessageID:   query.Message.MessageID,
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	if err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		log.Printf("更新设置菜单失败: %v", err)
+	}
+
+	return h.answerCallback(ctx, query, "", false)
+}
+
+// floodSettingsUpdate 根据按钮参数生成防刷屏配置的修改函数
+func floodSettingsUpdate(args []string) func(s *ChatSettings) {
+	switch args[0] {
+	case "action":
+		return func(s *ChatSettings) {
+			s.Flood.Action = nextFloodAction(s.Flood.Action)
+		}
+	case "limit", "window":
+		if len(args) < 2 {
+			return nil
+		}
+		delta, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil
+		}
+		field := args[0]
+		return func(s *ChatSettings) {
+			value := &s.Flood.Limit
+			if field == "window" {
+				value = &s.Flood.Window
+			}
+			if *value+delta >= 1 {
+				*value += delta
+			}
+		}
+	}
+	return nil
+}
+
+// nextFloodAction 返回菜单中的下一个防刷屏动作
+func nextFloodAction(current string) string {
+	for i, action := range floodActions {
+		if action == current {
+			return floodActions[(i+1)%len(floodActions)]
+		}
+	}
+	return floodActions[0]
+}
+
+// renderSettingsPage 渲染设置菜单的某一页
+func renderSettingsPage(s *ChatSettings, page string) (string, *InlineKeyboardMarkup) {
+	back := []InlineKeyboardButton{settingsButton("⬅️ 返回", "page:home")}
+
+	var text strings.Builder
+	var rows [][]InlineKeyboardButton
+
+	switch page {
+	case "lang":
+		text.WriteString("🌐 界面语言\n\n请选择本群使用的语言:")
+		for _, lang := range supportedLanguages {
+			rows = append(rows, []InlineKeyboardButton{
+				settingsButton(checkMark(s.Language == lang.Code)+" "+lang.Label, "lang:"+lang.Code),
+			})
+		}
+		rows = append(rows, back)
+
+	case "modules":
+		text.WriteString("🧩 功能模块\n\n点击按钮开启或关闭对应功能:")
+		for _, m := range knownModules {
+			rows = append(rows, []InlineKeyboardButton{
+				settingsButton(checkMark(s.IsModuleEnabled(m.Key))+" "+m.Label, "mod:"+m.Key),
+			})
+		}
+		rows = append(rows, back)
+
+	case "flood":
+		text.WriteString(fmt.Sprintf("🌊 防刷屏\n\n阈值: %d 秒内最多 %d 条消息\n触发动作: %s",
+			s.Flood.Window, s.Flood.Limit, floodActionLabels[s.Flood.Action]))
+		if s.Flood.Action == FloodActionMute {
+			text.WriteString("\n禁言时长: " + formatDuration(s.Flood.muteDuration()))
+		}
+		rows = append(rows,
+			[]InlineKeyboardButton{
+				settingsButton("消息数 -1", "flood:limit:-1"),
+				settingsButton("消息数 +1", "flood:limit:1"),
+			},
+			[]InlineKeyboardButton{
+				settingsButton("时间窗口 -1", "flood:window:-1"),
+				settingsButton("时间窗口 +1", "flood:window:1"),
+			},
+			[]InlineKeyboardButton{
+				settingsButton("动作: "+floodActionLabels[s.Flood.Action], "flood:action"),
+			},
+			back,
+		)
+
+	case "log":
+		text.WriteString("📋 日志频道\n\n")
+		if s.LogChannelID != 0 {
+			text.WriteString(fmt.Sprintf("当前绑定: %d", s.LogChannelID))
+			rows = append(rows, []InlineKeyboardButton{settingsButton("🔓 解除绑定", "unlog")})
+		} else {
+			text.WriteString("当前未绑定日志频道\n\n在日志频道中发送 /setlog 获取绑定码，再到本群发送 /setlog <绑定码>")
+		}
+		rows = append(rows, back)
+
+	case "targets":
+		text.WriteString("📤 转发目标\n\n")
+		if len(s.ForwardTargets) == 0 {
+			text.WriteString("暂无转发目标")
+		}
+		for _, id := range s.ForwardTargets {
+			rows = append(rows, []InlineKeyboardButton{
+				settingsButton(fmt.Sprintf("❌ %d", id), fmt.Sprintf("deltarget:%d", id)),
+			})
+		}
+		text.WriteString("\n\n使用 /settings addtarget <群组ID> 添加")
+		rows = append(rows, back)
+
+	default:
+		text.WriteString(fmt.Sprintf("⚙️ 群组设置\n\n🌐 语言: %s\n👋 欢迎消息: %s\n🌊 防刷屏: %s",
+			languageLabel(s.Language),
+			onOff(s.WelcomeEnabled),
+			onOff(s.IsModuleEnabled(ModuleAntiFlood))))
+		rows = append(rows,
+			[]InlineKeyboardButton{
+				settingsButton("🌐 语言", "page:lang"),
+				settingsButton("🧩 功能模块", "page:modules"),
+			},
+			[]InlineKeyboardButton{
+				settingsButton("🌊 防刷屏", "page:flood"),
+				settingsButton("👋 欢迎: "+onOff(s.WelcomeEnabled), "welcome"),
+			},
+			[]InlineKeyboardButton{
+				settingsButton("📋 日志频道", "page:log"),
+				settingsButton("📤 转发目标", "page:targets"),
+			},
+			[]InlineKeyboardButton{settingsButton("✖️ 关闭", "close")},
+		)
+	}
+
+	return text.String(), &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// settingsButton 创建设置菜单按钮
+func settingsButton(text, data string) InlineKeyboardButton {
+	return InlineKeyboardButton{Text: text, CallbackData: settingsCallbackPrefix + data}
+}
+
+// isKnownModule 检查是否为已知功能模块
+func isKnownModule(module string) bool {
+	for _, m := range knownModules {
+		if m.Key == module {
+			return true
+		}
+	}
+	return false
+}
+
+// isSupportedLanguage 检查是否为支持的语言
+func isSupportedLanguage(code string) bool {
+	for _, lang := range supportedLanguages {
+		if lang.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// languageLabel 获取语言显示名称
+func languageLabel(code string) string {
+	for _, lang := range supportedLanguages {
+		if lang.Code == code {
+			return lang.Label
+		}
+	}
+	return code
+}
+
+// checkMark 返回开关状态图标
+func checkMark(on bool) string {
+	if on {
+		return "✅"
+	}
+	return "❌"
+}
+
+// onOff 返回开关状态文字
+func onOff(on bool) string {
+	if on {
+		return "开"
+	}
+	return "关"
+}
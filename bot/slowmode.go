@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSlowModeInterval 慢速模式允许的最大间隔
+const maxSlowModeInterval = time.Hour
+
+// slowModeTracker 记录每个用户上一条消息的时间
+type slowModeTracker struct {
+	mu   sync.Mutex
+	last map[int64]map[int64]time.Time
+}
+
+// newSlowModeTracker 创建慢速模式记录器
+func newSlowModeTracker() *slowModeTracker {
+	return &slowModeTracker{last: make(map[int64]map[int64]time.Time)}
+}
+
+// allow 检查用户距上一条消息是否已超过间隔，允许发送时记录本次时间
+func (t *slowModeTracker) allow(chatID, userID int64, interval time.Duration) bool {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	users, ok := t.last[chatID]
+	if !ok {
+		users = make(map[int64]time.Time)
+		t.last[chatID] = users
+	}
+
+	if last, ok := users[userID]; ok && now.Sub(last) < interval {
+		return false
+	}
+	users[userID] = now
+	return true
+}
+
+// prune 清理超过最大间隔的记录
+func (t *slowModeTracker) prune(ctx context.Context) {
+	cutoff := time.Now().Add(-maxSlowModeInterval)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for chatID, users := range t.last {
+		for userID, last := range users {
+			if last.Before(cutoff) {
+				delete(users, userID)
+			}
+		}
+		if len(users) == 0 {
+			delete(t.last, chatID)
+		}
+	}
+}
+
+// checkSlowMode 检查消息是否违反慢速模式，违反时删除消息并返回 true
+func (h *MessageHandler) checkSlowMode(ctx context.Context, message *Message) bool {
+	if message.Chat.Type == "private" || message.From == nil || message.SenderChat != nil {
+		return false
+	}
+
+	interval := h.settings.Get(message.Chat.ID).SlowMode
+	if interval <= 0 {
+		return false
+	}
+
+	if h.slowMode.allow(message.Chat.ID, message.From.ID, time.Duration(interval)*time.Second) {
+		return false
+	}
+
+	// 超出频率后才检查管理员身份，管理员不受限制
+	if h.isSenderAdmin(ctx, message) {
+		return false
+	}
+
+	if err := h.client.DeleteMessage(ctx, message.Chat.ID, message.MessageID); err != nil {
+		log.Printf("删除慢速模式消息失败: %v", err)
+	}
+	return true
+}
+
+// handleSlowModeCommand 处理 /slowmode <秒数|off> 命令
+func (h *MessageHandler) handleSlowModeCommand(ctx context.Context, message *Message, args []string) error {
+	if message.Chat.Type == "private" {
+		return h.sendReply(ctx, message, "❌ 请在群组中使用此命令")
+	}
+
+	if ok, err := h.requireRight(ctx, message, RightDeleteMessages); !ok {
+		return err
+	}
+
+	if len(args) == 0 {
+		interval := h.settings.Get(message.Chat.ID).SlowMode
+		if interval <= 0 {
+			return h.sendReply(ctx, message, "🐢 慢速模式: 关\n用法: /slowmode <秒数|off>")
+		}
+		return h.sendReply(ctx, message, fmt.Sprintf("🐢 慢速模式: 每位成员每 %d 秒最多发送 1 条消息\n用法: /slowmode <秒数|off>", interval))
+	}
+
+	interval := 0
+	if strings.ToLower(args[0]) != "off" {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || time.Duration(n)*time.Second > maxSlowModeInterval {
+			return h.sendReply(ctx, message, fmt.Sprintf("❌ 秒数必须在 1 到 %d 之间", int(maxSlowModeInterval/time.Second)))
+		}
+		interval = n
+	}
+
+	_, err := h.settings.Update(message.Chat.ID, func(s *ChatSettings) {
+		s.SlowMode = interval
+	})
+	if err != nil {
+		log.Printf("保存群组配置失败: %v", err)
+		return h.sendReply(ctx, message, "❌ 保存配置失败")
+	}
+
+	if interval == 0 {
+		return h.sendReply(ctx, message, "✅ 已关闭慢速模式")
+	}
+	return h.sendReply(ctx, message, fmt.Sprintf("✅ 已开启慢速模式: 每位成员每 %d 秒最多发送 1 条消息", interval))
+}
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Storage 基于JSON文件的简单持久化存储
+// 每个命名空间对应数据目录下的一个 <name>.json 文件
+type Storage struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewStorage 创建持久化存储，目录不存在时自动创建
+func NewStorage(dir string) (*Storage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data dir: %w", err)
+	}
+
+	return &Storage{dir: dir}, nil
+}
+
+// Load 读取命名空间数据到v，文件不存在时保持v不变
+func (s *Storage) Load(name string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Save 将v写入命名空间文件 (先写临时文件再重命名，避免中途失败写坏数据)
+func (s *Storage) Save(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpPath := s.path(name) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	if err := os.Rename(tmpPath, s.path(name)); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// path 返回命名空间对应的文件路径
+func (s *Storage) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
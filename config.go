@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +17,9 @@ type Config struct {
 	LogLevel          string
 	SuperAdmins       []int64
 	PollTimeout       int
+	DataDir           string
+	AllowedChats      []int64
+	AutoLeave         bool
 }
 
 // LoadConfig 从环境变量和.env文件加载配置
@@ -31,6 +35,7 @@ func LoadConfig() (*Config, error) {
 	config := &Config{
 		LogLevel:    "INFO",
 		PollTimeout: 30, // 默认30秒超时
+		DataDir:     "data",
 	}
 
 	// 必需的配置项
@@ -61,17 +66,51 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if dataDir := os.Getenv("DATA_DIR"); dataDir != "" {
+		config.DataDir = dataDir
+	}
+
 	// 超级管理员配置
+	// 格式: "123456789,987654321"
 	if adminIDs := os.Getenv("SUPER_ADMINS"); adminIDs != "" {
-		// 简单的逗号分隔解析，后续可以改进
-		// 格式: "123456789,987654321"
-		// 这里暂时不实现复杂解析，后续可以扩展
-		log.Printf("SUPER_ADMINS configuration detected, parsing not implemented yet")
+		config.SuperAdmins = parseIDList("SUPER_ADMINS", adminIDs)
+	}
+
+	// 聊天白名单配置
+	if chatIDs := os.Getenv("ALLOWED_CHATS"); chatIDs != "" {
+		config.AllowedChats = parseIDList("ALLOWED_CHATS", chatIDs)
+	}
+
+	if autoLeave := os.Getenv("AUTO_LEAVE"); autoLeave != "" {
+		if v, err := strconv.ParseBool(autoLeave); err == nil {
+			config.AutoLeave = v
+		} else {
+			log.Printf("Warning: Invalid AUTO_LEAVE value: %s", autoLeave)
+		}
 	}
 
 	return config, nil
 }
 
+// parseIDList 解析逗号分隔的ID列表，忽略无效项
+func parseIDList(name, value string) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			log.Printf("Warning: Invalid %s entry: %s", name, part)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 // Validate 验证配置的有效性
 func (c *Config) Validate() error {
 	if c.BotToken == "" {
@@ -105,4 +144,4 @@ func (c *Config) IsSuperAdmin(userID int64) bool {
 		}
 	}
 	return false
-} 
\ No newline at end of file
+}
@@ -53,7 +53,20 @@ func main() {
 	log.Printf("配置加载成功 - 日志级别: %s, 轮询超时: %d秒", config.LogLevel, config.PollTimeout)
 
 	// 创建Bot实例
-	safewBot := bot.NewBot(config.BotToken, config.PollTimeout)
+	safewBot, err := bot.NewBot(bot.Options{
+		Token:       config.BotToken,
+		PollTimeout: config.PollTimeout,
+		DataDir:     config.DataDir,
+
+		DefaultForwardTarget: config.ForwardTargetChat,
+
+		SuperAdmins:           config.SuperAdmins,
+		AllowedChats:          config.AllowedChats,
+		AutoLeaveUnauthorized: config.AutoLeave,
+	})
+	if err != nil {
+		log.Fatalf("创建Bot失败: %v", err)
+	}
 
 	// 创建可取消的上下文
 	ctx, cancel := context.WithCancel(context.Background())
@@ -63,10 +76,10 @@ func main() {
 	go func() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		
+
 		sig := <-sigChan
 		log.Printf("接收到信号 %v，正在关闭Bot...", sig)
-		
+
 		// 取消上下文，触发Bot停止
 		cancel()
 	}()
@@ -82,4 +95,4 @@ func main() {
 	}
 
 	log.Println("SafeW Bot已停止")
-} 
\ No newline at end of file
+}